@@ -0,0 +1,359 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package anomalydetection
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type DetectorService common.Service
+
+type DetectorServiceInterface interface {
+	Get(ctx context.Context, id string) (*DetectorResponse, error)
+	Create(ctx context.Context, detector *Detector) (*DetectorResponse, error)
+	Update(ctx context.Context, id string, detector *Detector) (*DetectorResponse, error)
+	Delete(ctx context.Context, id string) error
+	Search(ctx context.Context, query interface{}) (*DetectorSearchResponse, error)
+	Start(ctx context.Context, id string) error
+	Stop(ctx context.Context, id string) error
+	Preview(ctx context.Context, id string, start time.Time, end time.Time) (*Preview, error)
+	Profile(ctx context.Context, id string, types ...string) (*Profile, error)
+	Stats(ctx context.Context) (*Stats, error)
+}
+
+type Detector struct {
+	Name              string                 `json:"name"`
+	Description       string                 `json:"description,omitempty"`
+	TimeField         string                 `json:"time_field"`
+	Indices           []string               `json:"indices"`
+	FeatureAttributes []Feature              `json:"feature_attributes"`
+	FilterQuery       interface{}            `json:"filter_query,omitempty"`
+	DetectionInterval *Interval              `json:"detection_interval"`
+	WindowDelay       *Interval              `json:"window_delay,omitempty"`
+	CategoryField     []string               `json:"category_field,omitempty"`
+	ShingleSize       int                    `json:"shingle_size,omitempty"`
+	SchemaVersion     int                    `json:"schema_version,omitempty"`
+	LastUpdateTime    int64                  `json:"last_update_time,omitempty"`
+	UIMetadata        map[string]interface{} `json:"ui_metadata,omitempty"`
+}
+
+// Feature is aggregated by an aggregation query, f.e. {"sum_bytes":{"sum":{"field":"bytes"}}}
+type Feature struct {
+	ID               string                 `json:"feature_id,omitempty"`
+	Name             string                 `json:"feature_name"`
+	Enabled          bool                   `json:"feature_enabled"`
+	AggregationQuery map[string]interface{} `json:"aggregation_query"`
+}
+
+type Interval struct {
+	Period Period `json:"period"`
+}
+
+type Period struct {
+	Interval int    `json:"interval"`
+	Unit     string `json:"unit"`
+}
+
+// NewInterval returns an interval of the given length in minutes, the unit used by the dashboards plugin
+func NewInterval(minutes int) *Interval {
+	return &Interval{Period: Period{Interval: minutes, Unit: "Minutes"}}
+}
+
+type DetectorResponse struct {
+	ID          string       `json:"_id"`
+	Version     int64        `json:"_version"`
+	SeqNo       int64        `json:"_seq_no"`
+	PrimaryTerm int64        `json:"_primary_term"`
+	Detector    *Detector    `json:"anomaly_detector"`
+	Job         *DetectorJob `json:"anomaly_detector_job,omitempty"`
+}
+
+type DetectorJob struct {
+	Name           string          `json:"name"`
+	Schedule       json.RawMessage `json:"schedule"`
+	WindowDelay    *Interval       `json:"window_delay"`
+	Enabled        bool            `json:"enabled"`
+	EnabledTime    int64           `json:"enabled_time"`
+	DisabledTime   int64           `json:"disabled_time"`
+	LastUpdateTime int64           `json:"last_update_time"`
+	LockDuration   int64           `json:"lock_duration_seconds"`
+}
+
+type DetectorSearchResponse struct {
+	Took         int64           `json:"took"`
+	TimedOut     bool            `json:"timed_out"`
+	Hits         DetectorHits    `json:"hits"`
+	Aggregations json.RawMessage `json:"aggregations,omitempty"`
+}
+
+type DetectorHits struct {
+	Total    SearchTotal   `json:"total"`
+	MaxScore float64       `json:"max_score"`
+	Hits     []DetectorHit `json:"hits"`
+}
+
+type DetectorHit struct {
+	Index       string   `json:"_index"`
+	ID          string   `json:"_id"`
+	Version     int64    `json:"_version"`
+	SeqNo       int64    `json:"_seq_no"`
+	PrimaryTerm int64    `json:"_primary_term"`
+	Score       float64  `json:"_score"`
+	Detector    Detector `json:"_source"`
+}
+
+type SearchTotal struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+type previewRequest struct {
+	PeriodStart int64 `json:"period_start"`
+	PeriodEnd   int64 `json:"period_end"`
+}
+
+type Preview struct {
+	AnomalyResults []AnomalyResult `json:"anomaly_result"`
+	Detector       *Detector       `json:"anomaly_detector"`
+}
+
+type Profile struct {
+	State            string         `json:"state"`
+	Error            string         `json:"error,omitempty"`
+	Models           []ModelProfile `json:"models,omitempty"`
+	ShingleSize      int            `json:"shingle_size,omitempty"`
+	CoordinatingNode string         `json:"coordinating_node,omitempty"`
+	TotalSizeInBytes int64          `json:"total_size_in_bytes,omitempty"`
+	InitProgress     *InitProgress  `json:"init_progress,omitempty"`
+	TotalEntities    int64          `json:"total_entities,omitempty"`
+	ActiveEntities   int64          `json:"active_entities,omitempty"`
+}
+
+type ModelProfile struct {
+	ModelID          string `json:"model_id"`
+	ModelSizeInBytes int64  `json:"model_size_in_bytes"`
+	NodeID           string `json:"node_id"`
+}
+
+type InitProgress struct {
+	Percentage           string `json:"percentage"`
+	EstimatedMinutesLeft int64  `json:"estimated_minutes_left"`
+	NeededShingles       int64  `json:"needed_shingles"`
+}
+
+type Stats struct {
+	AnomalyDetectorsIndexStatus    string               `json:"anomaly_detectors_index_status"`
+	AnomalyResultsIndexStatus      string               `json:"anomaly_results_index_status"`
+	ModelsCheckpointIndexStatus    string               `json:"models_checkpoint_index_status"`
+	AnomalyDetectionJobIndexStatus string               `json:"anomaly_detection_job_index_status"`
+	AnomalyDetectionStateStatus    string               `json:"anomaly_detection_state_status"`
+	DetectorCount                  int64                `json:"detector_count"`
+	Nodes                          map[string]NodeStats `json:"nodes"`
+}
+
+type NodeStats struct {
+	ExecuteRequestCount   int64        `json:"ad_execute_request_count"`
+	ExecuteFailureCount   int64        `json:"ad_execute_failure_count"`
+	HCExecuteRequestCount int64        `json:"ad_hc_execute_request_count"`
+	HCExecuteFailureCount int64        `json:"ad_hc_execute_failure_count"`
+	Models                []ModelStats `json:"models"`
+}
+
+type ModelStats struct {
+	DetectorID string `json:"detector_id"`
+	ModelID    string `json:"model_id"`
+	ModelType  string `json:"model_type"`
+}
+
+// Get a single detector by id, including its job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#get-detector
+func (s *DetectorService) Get(ctx context.Context, id string) (*DetectorResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.get")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id + "?job=true"
+
+	var detector *DetectorResponse
+
+	err := s.Client.Get(ctx, endpoint, &detector)
+	if err != nil {
+		return nil, err
+	}
+
+	return detector, nil
+}
+
+// Create a detector, the id is assigned by the plugin
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#create-detector
+func (s *DetectorService) Create(ctx context.Context, detector *Detector) (*DetectorResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.create")
+	defer done()
+
+	var response *DetectorResponse
+
+	err := s.Client.Send(ctx, common.AnomalyDetectorsEndpoint, http.MethodPost, detector, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Update a detector, the detector has to be stopped
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#update-detector
+func (s *DetectorService) Update(ctx context.Context, id string, detector *Detector) (*DetectorResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.update")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id
+
+	var response *DetectorResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, detector, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Delete a detector by id, the detector has to be stopped
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#delete-detector
+func (s *DetectorService) Delete(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.delete")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
+
+// Search detectors with a query, f.e. {"query":{"match":{"name":"http"}}}
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#search-detector
+func (s *DetectorService) Search(ctx context.Context, query interface{}) (*DetectorSearchResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.search")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + "_search"
+
+	var response *DetectorSearchResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, query, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Start the job of a detector
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#start-detector-job
+func (s *DetectorService) Start(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.start")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id + "/_start"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Stop the job of a detector
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#stop-detector-job
+func (s *DetectorService) Stop(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.stop")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id + "/_stop"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Preview the anomalies a detector finds in the given period of the existing data
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#preview-detector
+func (s *DetectorService) Preview(ctx context.Context, id string, start time.Time, end time.Time) (*Preview, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.preview")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id + "/_preview"
+
+	request := &previewRequest{
+		PeriodStart: toMillis(start),
+		PeriodEnd:   toMillis(end),
+	}
+
+	var preview *Preview
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, request, &preview)
+	if err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+// Profile returns the state of a detector and its models. Without types the default profile is returned,
+// otherwise only the given types (f.e. "state", "init_progress", "models").
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#profile-detector
+func (s *DetectorService) Profile(ctx context.Context, id string, types ...string) (*Profile, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.profile")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + id + "/_profile"
+
+	if len(types) > 0 {
+		endpoint = endpoint + "/" + strings.Join(types, ",")
+	}
+
+	var profile *Profile
+
+	err := s.Client.Get(ctx, endpoint, &profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// Stats returns the cluster and node statistics of the plugin
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#get-stats
+func (s *DetectorService) Stats(ctx context.Context) (*Stats, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.detectors.stats")
+	defer done()
+
+	var stats *Stats
+
+	err := s.Client.Get(ctx, common.AnomalyDetectionStatsEndpoint, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
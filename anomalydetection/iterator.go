@@ -0,0 +1,155 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package anomalydetection
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultPollInterval = time.Minute
+	DefaultPageSize     = 100
+)
+
+type IteratorOptions struct {
+	// PollInterval is the time waited between searches while there are no new anomalies
+	PollInterval time.Duration
+
+	// PageSize is the maximum number of anomalies returned by one call of Next
+	PageSize int
+
+	// MinGrade is the minimum anomaly grade of the returned results. Results with a grade of 0 (no anomaly)
+	// are never returned.
+	MinGrade float64
+}
+
+// AnomalyIterator yields the anomalies of a detector as they are found. The checkpoint is the execution end
+// time of the last anomaly returned, it can be persisted to resume after a restart.
+type AnomalyIterator struct {
+	service    ResultServiceInterface
+	detectorID string
+	options    IteratorOptions
+
+	checkpoint time.Time
+
+	// ids of the results returned with the execution end time of the checkpoint, which are
+	// found again by the next search
+	seen map[string]bool
+}
+
+// NewAnomalyIterator returns an iterator over the anomalies of a detector found after the checkpoint
+func NewAnomalyIterator(service ResultServiceInterface, detectorID string, checkpoint time.Time, options *IteratorOptions) *AnomalyIterator {
+	it := &AnomalyIterator{
+		service:    service,
+		detectorID: detectorID,
+		checkpoint: checkpoint,
+		seen:       map[string]bool{},
+	}
+
+	if options != nil {
+		it.options = *options
+	}
+	if it.options.PollInterval <= 0 {
+		it.options.PollInterval = DefaultPollInterval
+	}
+	if it.options.PageSize <= 0 {
+		it.options.PageSize = DefaultPageSize
+	}
+
+	return it
+}
+
+// Checkpoint returns the execution end time of the last anomaly returned by Next
+func (it *AnomalyIterator) Checkpoint() time.Time {
+	return it.checkpoint
+}
+
+// Next blocks until new anomalies are found and returns them ordered by their execution end time. It
+// returns the error of the context if it is done before.
+func (it *AnomalyIterator) Next(ctx context.Context) ([]AnomalyResult, error) {
+	for {
+		results, err := it.poll(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(results) > 0 {
+			return results, nil
+		}
+
+		timer := time.NewTimer(it.options.PollInterval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (it *AnomalyIterator) poll(ctx context.Context) ([]AnomalyResult, error) {
+	grade := map[string]interface{}{"gt": 0}
+	if it.options.MinGrade > 0 {
+		grade = map[string]interface{}{"gte": it.options.MinGrade}
+	}
+
+	query := map[string]interface{}{
+		"size": it.options.PageSize + len(it.seen),
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": []interface{}{
+					map[string]interface{}{
+						"term": map[string]interface{}{"detector_id": it.detectorID},
+					},
+					map[string]interface{}{
+						"range": map[string]interface{}{"anomaly_grade": grade},
+					},
+					map[string]interface{}{
+						"range": map[string]interface{}{
+							"execution_end_time": map[string]interface{}{
+								"gte":    toMillis(it.checkpoint),
+								"format": "epoch_millis",
+							},
+						},
+					},
+				},
+			},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"execution_end_time": map[string]interface{}{"order": "asc"}},
+		},
+	}
+
+	response, err := it.service.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []AnomalyResult
+
+	for _, hit := range response.Hits.Hits {
+		if it.seen[hit.ID] {
+			continue
+		}
+
+		if len(results) == it.options.PageSize {
+			break
+		}
+
+		end := hit.Result.ExecutionEnd()
+		if end.After(it.checkpoint) {
+			it.checkpoint = end
+			it.seen = map[string]bool{}
+		}
+		it.seen[hit.ID] = true
+
+		results = append(results, hit.Result)
+	}
+
+	return results, nil
+}
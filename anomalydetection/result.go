@@ -0,0 +1,103 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package anomalydetection
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"time"
+)
+
+type ResultService common.Service
+
+type ResultServiceInterface interface {
+	Search(ctx context.Context, query interface{}) (*ResultSearchResponse, error)
+}
+
+// AnomalyResult is the output of a detector for one detection interval. Times are epoch milliseconds.
+type AnomalyResult struct {
+	DetectorID         string        `json:"detector_id"`
+	DataStartTime      int64         `json:"data_start_time"`
+	DataEndTime        int64         `json:"data_end_time"`
+	ExecutionStartTime int64         `json:"execution_start_time"`
+	ExecutionEndTime   int64         `json:"execution_end_time"`
+	AnomalyScore       float64       `json:"anomaly_score"`
+	AnomalyGrade       float64       `json:"anomaly_grade"`
+	Confidence         float64       `json:"confidence"`
+	FeatureData        []FeatureData `json:"feature_data"`
+	Entity             []Entity      `json:"entity,omitempty"`
+	Error              string        `json:"error,omitempty"`
+	SchemaVersion      int           `json:"schema_version,omitempty"`
+}
+
+type FeatureData struct {
+	FeatureID   string  `json:"feature_id"`
+	FeatureName string  `json:"feature_name"`
+	Data        float64 `json:"data"`
+}
+
+// Entity identifies the value of a category field a result of a high cardinality detector belongs to
+type Entity struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type ResultSearchResponse struct {
+	Took         int64           `json:"took"`
+	TimedOut     bool            `json:"timed_out"`
+	Hits         ResultHits      `json:"hits"`
+	Aggregations json.RawMessage `json:"aggregations,omitempty"`
+}
+
+type ResultHits struct {
+	Total    SearchTotal `json:"total"`
+	MaxScore float64     `json:"max_score"`
+	Hits     []ResultHit `json:"hits"`
+}
+
+type ResultHit struct {
+	Index  string        `json:"_index"`
+	ID     string        `json:"_id"`
+	Score  float64       `json:"_score"`
+	Result AnomalyResult `json:"_source"`
+}
+
+// Search anomaly results of all detectors with a query
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ad/api/#search-detector-result
+func (s *ResultService) Search(ctx context.Context, query interface{}) (*ResultSearchResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "anomaly_detection.results.search")
+	defer done()
+
+	endpoint := common.AnomalyDetectorsEndpoint + "results/_search"
+
+	var response *ResultSearchResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, query, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (r *AnomalyResult) DataStart() time.Time {
+	return fromMillis(r.DataStartTime)
+}
+
+func (r *AnomalyResult) DataEnd() time.Time {
+	return fromMillis(r.DataEndTime)
+}
+
+func (r *AnomalyResult) ExecutionEnd() time.Time {
+	return fromMillis(r.ExecutionEndTime)
+}
+
+func fromMillis(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
@@ -0,0 +1,475 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"net/url"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ActionIndex  = "index"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+
+	DefaultFlushItems    = 1000
+	DefaultFlushBytes    = 5 * 1024 * 1024
+	DefaultFlushInterval = 30 * time.Second
+	DefaultMaxRetries    = 3
+
+	contentType = "application/x-ndjson"
+)
+
+// ErrClosed is returned when an item is added to a closed indexer
+var ErrClosed = errors.New("bulk indexer is closed")
+
+// Config of an indexer, zero values are replaced by the defaults. Each worker buffers the NDJSON body of its
+// batch up to FlushItems or FlushBytes, the body is needed to retry the request and the items rejected with
+// status 429, so the memory used is about Workers * FlushBytes.
+type Config struct {
+	// Workers is the number of concurrent bulk requests, defaults to the number of CPUs
+	Workers int
+
+	// FlushItems, FlushBytes and FlushInterval trigger a bulk request of a worker when its batch
+	// reaches the number of items, the size of its body or the time since the last request
+	FlushItems    int
+	FlushBytes    int
+	FlushInterval time.Duration
+
+	// MaxRetries of items rejected with status 429 (too many requests), a negative value disables retries
+	MaxRetries int
+
+	// RetryBackoff returns the time to wait before a retry, defaults to an exponential backoff starting at
+	// 100ms up to 10s
+	RetryBackoff func(attempt int) time.Duration
+
+	// Index is used for all items without index
+	Index string
+
+	// Refresh is "true", "false" or "wait_for"
+	Refresh string
+
+	// OnError is called if a bulk request failed as a whole
+	OnError func(ctx context.Context, err error)
+}
+
+// Item is an operation of a bulk request. Body is the document for index and create, the update request
+// (f.e. {"doc":{...}}) for update and nil for delete.
+type Item struct {
+	Action        string
+	Index         string
+	ID            string
+	Routing       string
+	IfSeqNo       *int64
+	IfPrimaryTerm *int64
+	Body          interface{}
+
+	// OnSuccess is called after the item was executed successfully
+	OnSuccess func(ctx context.Context, item Item, result ResultItem)
+
+	// OnFailure is called if the item failed, err is set if the bulk request failed as a whole
+	OnFailure func(ctx context.Context, item Item, result ResultItem, err error)
+}
+
+// ResultItem is the result of an item in the bulk response
+type ResultItem struct {
+	Index       string     `json:"_index"`
+	ID          string     `json:"_id"`
+	Version     int64      `json:"_version"`
+	Result      string     `json:"result"`
+	Status      int        `json:"status"`
+	SeqNo       int64      `json:"_seq_no"`
+	PrimaryTerm int64      `json:"_primary_term"`
+	Error       *ItemError `json:"error,omitempty"`
+}
+
+type ItemError struct {
+	Type   string `json:"type"`
+	Reason string `json:"reason"`
+}
+
+func (e *ItemError) Error() string {
+	return e.Type + ": " + e.Reason
+}
+
+// Stats of an indexer
+type Stats struct {
+	Added        uint64
+	Indexed      uint64
+	Failed       uint64
+	Retried      uint64
+	Requests     uint64
+	FlushedBytes uint64
+}
+
+type Indexer struct {
+	// counters first to keep them 64-bit aligned for the atomic operations
+	stats Stats
+
+	client common.ClientInterface
+	config Config
+
+	queue  chan Item
+	mu     sync.RWMutex
+	closed bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type meta struct {
+	Index         string `json:"_index,omitempty"`
+	ID            string `json:"_id,omitempty"`
+	Routing       string `json:"routing,omitempty"`
+	IfSeqNo       *int64 `json:"if_seq_no,omitempty"`
+	IfPrimaryTerm *int64 `json:"if_primary_term,omitempty"`
+}
+
+type response struct {
+	Errors bool                     `json:"errors"`
+	Items  []map[string]*ResultItem `json:"items"`
+}
+
+// pending is an item of a batch with the position of its lines in the body
+type pending struct {
+	item       Item
+	start, end int
+}
+
+type batch struct {
+	buf   bytes.Buffer
+	items []pending
+}
+
+// NewIndexer starts the workers of a bulk indexer, the indexer has to be closed to flush the remaining
+// items
+func NewIndexer(client common.ClientInterface, config *Config) *Indexer {
+	i := &Indexer{client: client}
+
+	if config != nil {
+		i.config = *config
+	}
+	if i.config.Workers <= 0 {
+		i.config.Workers = runtime.NumCPU()
+	}
+	if i.config.FlushItems <= 0 {
+		i.config.FlushItems = DefaultFlushItems
+	}
+	if i.config.FlushBytes <= 0 {
+		i.config.FlushBytes = DefaultFlushBytes
+	}
+	if i.config.FlushInterval <= 0 {
+		i.config.FlushInterval = DefaultFlushInterval
+	}
+	if i.config.MaxRetries < 0 {
+		i.config.MaxRetries = 0
+	} else if i.config.MaxRetries == 0 {
+		i.config.MaxRetries = DefaultMaxRetries
+	}
+	if i.config.RetryBackoff == nil {
+		i.config.RetryBackoff = defaultBackoff
+	}
+
+	// the queue is unbuffered, a caller blocks until a worker takes the item
+	i.queue = make(chan Item)
+	i.ctx, i.cancel = context.WithCancel(context.Background())
+
+	for n := 0; n < i.config.Workers; n++ {
+		i.wg.Add(1)
+		go i.worker()
+	}
+
+	return i
+}
+
+// Add an item, blocks until a worker accepts it (backpressure) or the context is done
+func (i *Indexer) Add(ctx context.Context, item Item) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if i.closed {
+		return ErrClosed
+	}
+
+	select {
+	case i.queue <- item:
+		atomic.AddUint64(&i.stats.Added, 1)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run adds the items of a channel until it is closed or the context is done
+func (i *Indexer) Run(ctx context.Context, items <-chan Item) error {
+	for {
+		select {
+		case item, ok := <-items:
+			if !ok {
+				return nil
+			}
+
+			err := i.Add(ctx, item)
+			if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Close flushes the remaining items and stops the workers. If the context is done before, the pending
+// requests are canceled.
+func (i *Indexer) Close(ctx context.Context) error {
+	i.mu.Lock()
+	if !i.closed {
+		i.closed = true
+		close(i.queue)
+	}
+	i.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		i.cancel()
+		return nil
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the counters of the indexer
+func (i *Indexer) Stats() Stats {
+	return Stats{
+		Added:        atomic.LoadUint64(&i.stats.Added),
+		Indexed:      atomic.LoadUint64(&i.stats.Indexed),
+		Failed:       atomic.LoadUint64(&i.stats.Failed),
+		Retried:      atomic.LoadUint64(&i.stats.Retried),
+		Requests:     atomic.LoadUint64(&i.stats.Requests),
+		FlushedBytes: atomic.LoadUint64(&i.stats.FlushedBytes),
+	}
+}
+
+func (i *Indexer) worker() {
+	defer i.wg.Done()
+
+	ticker := time.NewTicker(i.config.FlushInterval)
+	defer ticker.Stop()
+
+	b := &batch{}
+
+	for {
+		select {
+		case item, ok := <-i.queue:
+			if !ok {
+				i.flush(b)
+				return
+			}
+
+			err := b.add(item, i.config.Index)
+			if err != nil {
+				atomic.AddUint64(&i.stats.Failed, 1)
+				if item.OnFailure != nil {
+					item.OnFailure(i.ctx, item, ResultItem{}, err)
+				}
+				continue
+			}
+
+			if len(b.items) >= i.config.FlushItems || b.buf.Len() >= i.config.FlushBytes {
+				i.flush(b)
+			}
+		case <-ticker.C:
+			i.flush(b)
+		}
+	}
+}
+
+// flush sends the batch and retries the items rejected with status 429 until they succeed or the
+// retries are exhausted
+func (i *Indexer) flush(b *batch) {
+	for attempt := 0; len(b.items) > 0; attempt++ {
+		retry := i.send(b, attempt < i.config.MaxRetries)
+
+		b = retry
+		if len(b.items) == 0 {
+			return
+		}
+
+		atomic.AddUint64(&i.stats.Retried, uint64(len(b.items)))
+
+		select {
+		case <-time.After(i.config.RetryBackoff(attempt + 1)):
+		case <-i.ctx.Done():
+			i.fail(b.items, i.ctx.Err())
+			return
+		}
+	}
+}
+
+// send executes a batch, resets it and returns a new batch of the items to retry
+func (i *Indexer) send(b *batch, retryable bool) *batch {
+	items := b.items
+	data := b.buf.Bytes()
+
+	defer func() {
+		b.items = nil
+		b.buf.Reset()
+	}()
+
+	atomic.AddUint64(&i.stats.Requests, 1)
+	atomic.AddUint64(&i.stats.FlushedBytes, uint64(len(data)))
+
+	// the buffer is sent without a copy, it is reset after the response was handled
+	body, err := i.client.DoRaw(i.ctx, &b.buf, contentType, i.endpoint(), http.MethodPost)
+	if err != nil {
+		if re, ok := err.(*common.ResponseError); ok && re.StatusCode == http.StatusTooManyRequests && retryable {
+			return retryBatch(data, items)
+		}
+
+		if i.config.OnError != nil {
+			i.config.OnError(i.ctx, err)
+		}
+		i.fail(items, err)
+
+		return &batch{}
+	}
+
+	var resp *response
+
+	err = json.Unmarshal(body, &resp)
+	if err == nil && (resp == nil || len(resp.Items) != len(items)) {
+		err = errors.New("unexpected number of items in bulk response")
+	}
+	if err != nil {
+		if i.config.OnError != nil {
+			i.config.OnError(i.ctx, err)
+		}
+		i.fail(items, err)
+
+		return &batch{}
+	}
+
+	var retry []pending
+
+	for n, p := range items {
+		var result ResultItem
+
+		for _, r := range resp.Items[n] {
+			if r != nil {
+				result = *r
+			}
+		}
+
+		switch {
+		case result.Status == http.StatusTooManyRequests && retryable:
+			retry = append(retry, p)
+		case result.Status >= http.StatusBadRequest || result.Error != nil:
+			atomic.AddUint64(&i.stats.Failed, 1)
+			if p.item.OnFailure != nil {
+				p.item.OnFailure(i.ctx, p.item, result, nil)
+			}
+		default:
+			atomic.AddUint64(&i.stats.Indexed, 1)
+			if p.item.OnSuccess != nil {
+				p.item.OnSuccess(i.ctx, p.item, result)
+			}
+		}
+	}
+
+	return retryBatch(data, retry)
+}
+
+func (i *Indexer) fail(items []pending, err error) {
+	atomic.AddUint64(&i.stats.Failed, uint64(len(items)))
+
+	for _, p := range items {
+		if p.item.OnFailure != nil {
+			p.item.OnFailure(i.ctx, p.item, ResultItem{}, err)
+		}
+	}
+}
+
+func (i *Indexer) endpoint() string {
+	if i.config.Refresh == "" {
+		return common.BulkEndpoint
+	}
+
+	return common.BulkEndpoint + "?refresh=" + url.QueryEscape(i.config.Refresh)
+}
+
+// add encodes the action and the body of an item as lines of the body
+func (b *batch) add(item Item, index string) error {
+	if item.Action == "" {
+		item.Action = ActionIndex
+	}
+	if item.Index == "" {
+		item.Index = index
+	}
+
+	start := b.buf.Len()
+	enc := json.NewEncoder(&b.buf)
+
+	err := enc.Encode(map[string]*meta{item.Action: {
+		Index:         item.Index,
+		ID:            item.ID,
+		Routing:       item.Routing,
+		IfSeqNo:       item.IfSeqNo,
+		IfPrimaryTerm: item.IfPrimaryTerm,
+	}})
+	if err == nil && item.Action != ActionDelete {
+		err = enc.Encode(item.Body)
+	}
+	if err != nil {
+		b.buf.Truncate(start)
+		return err
+	}
+
+	b.items = append(b.items, pending{item: item, start: start, end: b.buf.Len()})
+
+	return nil
+}
+
+// retryBatch copies the lines of the items to retry from the body of the previous request
+func retryBatch(data []byte, items []pending) *batch {
+	b := &batch{}
+
+	for _, p := range items {
+		start := b.buf.Len()
+		b.buf.Write(data[p.start:p.end])
+		b.items = append(b.items, pending{item: p.item, start: start, end: b.buf.Len()})
+	}
+
+	return b
+}
+
+func defaultBackoff(attempt int) time.Duration {
+	backoff := 100 * time.Millisecond << uint(attempt-1)
+	if backoff > 10*time.Second || backoff <= 0 {
+		return 10 * time.Second
+	}
+
+	return backoff
+}
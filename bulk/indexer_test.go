@@ -0,0 +1,452 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package bulk_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/WhizUs/go-opendistro"
+	"github.com/WhizUs/go-opendistro/bulk"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBulk emulates the bulk API. Items with an id starting with "reject" are rejected with status 429 as
+// often as the number following it, items with an id starting with "bad" fail with status 400.
+type fakeBulk struct {
+	mu       sync.Mutex
+	requests [][]string
+	bytes    int
+	rejected map[string]int
+
+	// status of the whole response, if set
+	status int
+}
+
+func (f *fakeBulk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []string
+	var items []map[string]interface{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var action map[string]map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for name, meta := range action {
+			id, _ := meta["_id"].(string)
+			ids = append(ids, id)
+
+			if name != bulk.ActionDelete {
+				scanner.Scan()
+			}
+
+			items = append(items, map[string]interface{}{name: f.result(meta["_index"], id)})
+		}
+	}
+
+	f.requests = append(f.requests, ids)
+	f.bytes += len(body)
+
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"type": "failure", "reason": "failed"}, "status": f.status})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": false, "items": items})
+}
+
+func (f *fakeBulk) result(index interface{}, id string) map[string]interface{} {
+	result := map[string]interface{}{"_index": index, "_id": id, "status": http.StatusCreated, "result": "created"}
+
+	switch {
+	case strings.HasPrefix(id, "reject"):
+		times, _ := strconv.Atoi(strings.TrimPrefix(id, "reject"))
+		if f.rejected[id] < times {
+			f.rejected[id]++
+			result["status"] = http.StatusTooManyRequests
+			result["error"] = map[string]string{"type": "es_rejected_execution_exception", "reason": "rejected"}
+		}
+	case strings.HasPrefix(id, "bad"):
+		result["status"] = http.StatusBadRequest
+		result["error"] = map[string]string{"type": "mapper_parsing_exception", "reason": "failed to parse"}
+	}
+
+	return result
+}
+
+func (f *fakeBulk) snapshot() ([][]string, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	requests := make([][]string, len(f.requests))
+	copy(requests, f.requests)
+
+	return requests, f.bytes
+}
+
+func newIndexer(t *testing.T, fake *fakeBulk, config *bulk.Config) *bulk.Indexer {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	if fake.rejected == nil {
+		fake.rejected = map[string]int{}
+	}
+
+	client, err := opendistro.NewClient(&opendistro.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.Logger = nil
+	client.Client.RetryMax = 0
+
+	if config.Index == "" {
+		config.Index = "docs"
+	}
+	if config.RetryBackoff == nil {
+		config.RetryBackoff = func(int) time.Duration { return time.Millisecond }
+	}
+
+	return bulk.NewIndexer(client, config)
+}
+
+// results records the callbacks of the items
+type results struct {
+	mu        sync.Mutex
+	succeeded []string
+	failed    map[string]bulk.ResultItem
+	errs      map[string]error
+}
+
+func (r *results) item(id string) bulk.Item {
+	return bulk.Item{
+		ID:   id,
+		Body: map[string]string{"id": id},
+		OnSuccess: func(ctx context.Context, item bulk.Item, result bulk.ResultItem) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.succeeded = append(r.succeeded, item.ID)
+		},
+		OnFailure: func(ctx context.Context, item bulk.Item, result bulk.ResultItem, err error) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if r.failed == nil {
+				r.failed = map[string]bulk.ResultItem{}
+				r.errs = map[string]error{}
+			}
+			r.failed[item.ID] = result
+			r.errs[item.ID] = err
+		},
+	}
+}
+
+func add(t *testing.T, indexer *bulk.Indexer, items ...bulk.Item) {
+	for _, item := range items {
+		if err := indexer.Add(context.Background(), item); err != nil {
+			t.Fatalf("add %s: %s", item.ID, err)
+		}
+	}
+}
+
+func closeIndexer(t *testing.T, indexer *bulk.Indexer) {
+	if err := indexer.Close(context.Background()); err != nil {
+		t.Fatalf("close: %s", err)
+	}
+}
+
+// eventually waits until the condition is true
+func eventually(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestFlushItems(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushItems: 2, FlushInterval: time.Hour})
+	r := &results{}
+
+	add(t, indexer, r.item("1"), r.item("2"), r.item("3"))
+
+	// the third item waits for the next flush
+	eventually(t, func() bool { requests, _ := fake.snapshot(); return len(requests) == 1 })
+
+	add(t, indexer, r.item("4"), r.item("5"))
+	closeIndexer(t, indexer)
+
+	requests, flushedBytes := fake.snapshot()
+	want := [][]string{{"1", "2"}, {"3", "4"}, {"5"}}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for n := range want {
+		if strings.Join(requests[n], ",") != strings.Join(want[n], ",") {
+			t.Errorf("request %d = %v, want %v", n, requests[n], want[n])
+		}
+	}
+
+	stats := indexer.Stats()
+	if stats.Added != 5 || stats.Indexed != 5 || stats.Failed != 0 || stats.Requests != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.FlushedBytes != uint64(flushedBytes) {
+		t.Errorf("flushed bytes = %d, want %d", stats.FlushedBytes, flushedBytes)
+	}
+	if len(r.succeeded) != 5 {
+		t.Errorf("succeeded = %v", r.succeeded)
+	}
+}
+
+func TestFlushBytes(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushBytes: 1, FlushInterval: time.Hour})
+
+	add(t, indexer, bulk.Item{ID: "1", Body: "{}"}, bulk.Item{ID: "2", Body: "{}"})
+
+	// every item exceeds the flush bytes, so it is sent before the next item is taken
+	eventually(t, func() bool { requests, _ := fake.snapshot(); return len(requests) == 2 })
+
+	closeIndexer(t, indexer)
+
+	if stats := indexer.Stats(); stats.Requests != 2 || stats.Indexed != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestFlushInterval(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushInterval: 10 * time.Millisecond})
+	defer closeIndexer(t, indexer)
+
+	add(t, indexer, bulk.Item{ID: "1", Body: "{}"})
+
+	eventually(t, func() bool { return indexer.Stats().Indexed == 1 })
+}
+
+func TestCloseFlushesAllWorkers(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 4, FlushInterval: time.Hour})
+
+	for n := 0; n < 100; n++ {
+		add(t, indexer, bulk.Item{ID: strconv.Itoa(n), Body: "{}"})
+	}
+	closeIndexer(t, indexer)
+
+	if stats := indexer.Stats(); stats.Added != 100 || stats.Indexed != 100 || stats.Requests > 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if err := indexer.Add(context.Background(), bulk.Item{ID: "late"}); !errors.Is(err, bulk.ErrClosed) {
+		t.Errorf("add after close = %v, want %v", err, bulk.ErrClosed)
+	}
+}
+
+func TestRetryRejectedItems(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushInterval: time.Hour})
+	r := &results{}
+
+	add(t, indexer, r.item("1"), r.item("reject2"), r.item("2"))
+	closeIndexer(t, indexer)
+
+	requests, _ := fake.snapshot()
+	want := []string{"1,reject2,2", "reject2", "reject2"}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	for n := range want {
+		if got := strings.Join(requests[n], ","); got != want[n] {
+			t.Errorf("request %d = %s, want %s", n, got, want[n])
+		}
+	}
+
+	stats := indexer.Stats()
+	if stats.Indexed != 3 || stats.Retried != 2 || stats.Failed != 0 || stats.Requests != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if len(r.succeeded) != 3 || len(r.failed) != 0 {
+		t.Errorf("succeeded = %v, failed = %v", r.succeeded, r.failed)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushInterval: time.Hour, MaxRetries: 1})
+	r := &results{}
+
+	add(t, indexer, r.item("reject5"))
+	closeIndexer(t, indexer)
+
+	if requests, _ := fake.snapshot(); len(requests) != 2 {
+		t.Errorf("requests = %v, want the request and one retry", requests)
+	}
+
+	result, ok := r.failed["reject5"]
+	if !ok || result.Status != http.StatusTooManyRequests || result.Error == nil || r.errs["reject5"] != nil {
+		t.Errorf("failure = %+v, %v", result, r.errs["reject5"])
+	}
+	if stats := indexer.Stats(); stats.Failed != 1 || stats.Retried != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRetriesDisabled(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushInterval: time.Hour, MaxRetries: -1})
+	r := &results{}
+
+	add(t, indexer, r.item("reject1"))
+	closeIndexer(t, indexer)
+
+	if requests, _ := fake.snapshot(); len(requests) != 1 {
+		t.Errorf("requests = %v, want no retry", requests)
+	}
+	if _, ok := r.failed["reject1"]; !ok {
+		t.Errorf("failed = %v", r.failed)
+	}
+}
+
+func TestItemFailure(t *testing.T) {
+	fake := &fakeBulk{}
+	indexer := newIndexer(t, fake, &bulk.Config{Workers: 1, FlushInterval: time.Hour})
+	r := &results{}
+
+	add(t, indexer, r.item("1"), r.item("bad"))
+	closeIndexer(t, indexer)
+
+	result := r.failed["bad"]
+	if result.Status != http.StatusBadRequest || result.Error == nil || result.Error.Type != "mapper_parsing_exception" {
+		t.Errorf("failure = %+v", result)
+	}
+	if len(r.succeeded) != 1 || r.succeeded[0] != "1" {
+		t.Errorf("succeeded = %v", r.succeeded)
+	}
+	if stats := indexer.Stats(); stats.Indexed != 1 || stats.Failed != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRequestFailure(t *testing.T) {
+	fake := &fakeBulk{status: http.StatusBadRequest}
+
+	var mu sync.Mutex
+	var requestErrs []error
+
+	indexer := newIndexer(t, fake, &bulk.Config{
+		Workers:       1,
+		FlushInterval: time.Hour,
+		OnError: func(ctx context.Context, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			requestErrs = append(requestErrs, err)
+		},
+	})
+	r := &results{}
+
+	add(t, indexer, r.item("1"), r.item("2"))
+	closeIndexer(t, indexer)
+
+	if len(requestErrs) != 1 {
+		t.Errorf("request errors = %v, want one", requestErrs)
+	}
+	if len(r.failed) != 2 || r.errs["1"] == nil || r.errs["2"] == nil {
+		t.Errorf("failed = %v, errors = %v", r.failed, r.errs)
+	}
+	if stats := indexer.Stats(); stats.Failed != 2 || stats.Indexed != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestRetryRejectedRequest(t *testing.T) {
+	fake := &fakeBulk{status: http.StatusTooManyRequests}
+
+	indexer := newIndexer(t, fake, &bulk.Config{
+		Workers:       1,
+		FlushInterval: time.Hour,
+		RetryBackoff: func(int) time.Duration {
+			// the cluster accepts the request after the first rejection
+			fake.mu.Lock()
+			fake.status = 0
+			fake.mu.Unlock()
+
+			return time.Millisecond
+		},
+	})
+
+	add(t, indexer, bulk.Item{ID: "1", Body: "{}"}, bulk.Item{ID: "2", Body: "{}"})
+	closeIndexer(t, indexer)
+
+	if requests, _ := fake.snapshot(); len(requests) != 2 {
+		t.Errorf("requests = %v, want the request and its retry", requests)
+	}
+	if stats := indexer.Stats(); stats.Indexed != 2 || stats.Retried != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAddBlocksUntilContextDone(t *testing.T) {
+	block := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		http.Error(w, "blocked", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	defer close(block)
+
+	client, err := opendistro.NewClient(&opendistro.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.Logger = nil
+	client.Client.RetryMax = 0
+
+	indexer := bulk.NewIndexer(client, &bulk.Config{Workers: 1, FlushItems: 1, FlushInterval: time.Hour})
+
+	// the only worker is busy sending the first item, so the queue does not accept the second one
+	add(t, indexer, bulk.Item{ID: "1", Body: "{}"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := indexer.Add(ctx, bulk.Item{ID: "2", Body: "{}"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("add = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer closeCancel()
+
+	if err := indexer.Close(closeCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("close = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -10,19 +10,65 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/WhizUs/go-opendistro/anomalydetection"
+	"github.com/WhizUs/go-opendistro/cluster"
 	"github.com/WhizUs/go-opendistro/common"
+	"github.com/WhizUs/go-opendistro/document"
+	"github.com/WhizUs/go-opendistro/indexmanagement"
+	"github.com/WhizUs/go-opendistro/indices"
+	"github.com/WhizUs/go-opendistro/knn"
+	"github.com/WhizUs/go-opendistro/performanceanalyzer"
 	"github.com/WhizUs/go-opendistro/security"
+	"github.com/WhizUs/go-opendistro/snapshot"
+	"github.com/WhizUs/go-opendistro/sql"
+	"github.com/WhizUs/go-opendistro/tasks"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/hashicorp/go-rootcerts"
+	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 )
 
 type ClientConfig struct {
 	Username, Password, BaseURL string
 
+	// Addresses are the base URLs of multiple nodes of the cluster, requests are distributed by the
+	// NodeSelector and fail over to the next node on connection errors. BaseURL is used if empty.
+	Addresses    []string
+	NodeSelector NodeSelector
+
+	// ResurrectTimeout is the time a node is excluded after a connection error, it doubles with every
+	// consecutive error. It defaults to DefaultResurrectTimeout.
+	ResurrectTimeout time.Duration
+
+	// SniffInterval enables the discovery of the nodes of the cluster via the nodes info API, it is
+	// disabled if the addresses have a path (see ErrSniffingDisabled)
+	SniffInterval time.Duration
+
+	// RateLimit limits all requests of the client, EndpointRateLimits limit the requests by endpoint
+	// class, which is the plugin of a plugin API (f.e. "security") or the API otherwise (f.e. "bulk")
+	RateLimit          *RateLimit
+	EndpointRateLimits map[string]*RateLimit
+
+	// Instrumentations observe the requests of the client, f.e. the collector of the metrics package or
+	// the tracer of the tracing package
+	Instrumentations []common.Instrumentation
+
+	// Flavor selects the paths of the plugin APIs (Open Distro or OpenSearch), it defaults to
+	// FlavorOpenDistro
+	Flavor Flavor
+
+	// PerformanceAnalyzerURL is the base URL of the performance analyzer, which listens on its own port.
+	// It defaults to the base URL with port 9600.
+	PerformanceAnalyzerURL string
+
 	TLSConfig *TLSConfig
 }
 
@@ -42,7 +88,38 @@ type Client struct {
 
 	common common.Service
 
+	pool      *nodePool
+	done      chan struct{}
+	closeOnce *sync.Once
+	flavor    *flavorState
+	info      *serverInfoState
+	limiters  *limiters
+
+	instrumentation common.Instrumentation
+
 	Security securityClient
+
+	Cluster clusterClient
+
+	Indices indicesClient
+
+	Documents document.DocumentServiceInterface
+	Reindex   document.ReindexServiceInterface
+
+	Snapshot snapshotClient
+
+	Tasks tasks.TaskServiceInterface
+
+	SQL sql.SQLServiceInterface
+	PPL sql.PPLServiceInterface
+
+	AnomalyDetection anomalyDetectionClient
+
+	KNN knn.KNNServiceInterface
+
+	PerformanceAnalyzer performanceAnalyzerClient
+
+	IndexManagement indexManagementClient
 }
 
 type securityClient struct {
@@ -54,6 +131,43 @@ type securityClient struct {
 	Health       security.HealthServiceInterface
 }
 
+type clusterClient struct {
+	Health     cluster.HealthServiceInterface
+	Settings   cluster.SettingsServiceInterface
+	Stats      cluster.StatsServiceInterface
+	State      cluster.StateServiceInterface
+	Nodes      cluster.NodesServiceInterface
+	Allocation cluster.AllocationServiceInterface
+}
+
+type indicesClient struct {
+	Index     indices.IndexServiceInterface
+	Mappings  indices.MappingServiceInterface
+	Settings  indices.SettingsServiceInterface
+	Aliases   indices.AliasServiceInterface
+	Templates indices.TemplateServiceInterface
+}
+
+type snapshotClient struct {
+	Repositories snapshot.RepositoryServiceInterface
+	Snapshots    snapshot.SnapshotServiceInterface
+}
+
+type indexManagementClient struct {
+	Rollups    indexmanagement.RollupServiceInterface
+	Transforms indexmanagement.TransformServiceInterface
+}
+
+type performanceAnalyzerClient struct {
+	Metrics performanceanalyzer.MetricsServiceInterface
+	RCA     performanceanalyzer.RCAServiceInterface
+}
+
+type anomalyDetectionClient struct {
+	Detectors anomalydetection.DetectorServiceInterface
+	Results   anomalydetection.ResultServiceInterface
+}
+
 func NewClient(config *ClientConfig) (*Client, error) {
 	rc := retryablehttp.NewClient()
 
@@ -83,15 +197,61 @@ func NewClient(config *ClientConfig) (*Client, error) {
 		rc.HTTPClient.Transport = &http.Transport{TLSClientConfig: conf}
 	}
 
+	var instrumentation common.Instrumentation
+	if len(config.Instrumentations) > 0 {
+		instrumentation = instrumentations(config.Instrumentations)
+		rc.HTTPClient.Transport = &instrumentedTransport{
+			next:            rc.HTTPClient.Transport,
+			instrumentation: instrumentation,
+		}
+	}
+
+	addresses := config.Addresses
+	if len(addresses) == 0 && config.BaseURL != "" {
+		addresses = []string{config.BaseURL}
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" && len(addresses) > 0 {
+		baseURL = addresses[0]
+	}
+
 	c := &Client{
-		Client:   rc,
-		Username: config.Username,
-		Password: config.Password,
-		BaseURL:  config.BaseURL,
+		Client:    rc,
+		Username:  config.Username,
+		Password:  config.Password,
+		BaseURL:   baseURL,
+		done:      make(chan struct{}),
+		closeOnce: &sync.Once{},
+
+		instrumentation: instrumentation,
 	}
 
 	c.common.Client = c
 
+	flavor := config.Flavor
+	if flavor == "" {
+		flavor = FlavorOpenDistro
+	}
+	c.flavor = &flavorState{client: c, flavor: flavor}
+	c.info = &serverInfoState{client: c}
+	c.limiters = newLimiters(config.RateLimit, config.EndpointRateLimits)
+
+	if len(addresses) > 0 {
+		c.pool = newNodePool(addresses, config.NodeSelector, config.ResurrectTimeout)
+	}
+
+	if len(addresses) > 1 || config.SniffInterval > 0 {
+		// connection errors fail over to the next node instead of being retried on the same node
+		rc.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+			if err != nil && ctx.Err() == nil {
+				return false, nil
+			}
+
+			return retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		}
+	}
+
 	c.Security = securityClient{
 		Users:        (*security.UserService)(&c.common),
 		Roles:        (*security.RoleService)(&c.common),
@@ -101,65 +261,145 @@ func NewClient(config *ClientConfig) (*Client, error) {
 		Health:       (*security.HealthService)(&c.common),
 	}
 
+	c.Cluster = clusterClient{
+		Health:     (*cluster.HealthService)(&c.common),
+		Settings:   (*cluster.SettingsService)(&c.common),
+		Stats:      (*cluster.StatsService)(&c.common),
+		State:      (*cluster.StateService)(&c.common),
+		Nodes:      (*cluster.NodesService)(&c.common),
+		Allocation: (*cluster.AllocationService)(&c.common),
+	}
+
+	c.Indices = indicesClient{
+		Index:     (*indices.IndexService)(&c.common),
+		Mappings:  (*indices.MappingService)(&c.common),
+		Settings:  (*indices.SettingsService)(&c.common),
+		Aliases:   (*indices.AliasService)(&c.common),
+		Templates: (*indices.TemplateService)(&c.common),
+	}
+
+	c.Documents = (*document.DocumentService)(&c.common)
+	c.Reindex = (*document.ReindexService)(&c.common)
+
+	c.Snapshot = snapshotClient{
+		Repositories: (*snapshot.RepositoryService)(&c.common),
+		Snapshots:    (*snapshot.SnapshotService)(&c.common),
+	}
+
+	c.Tasks = (*tasks.TaskService)(&c.common)
+
+	c.SQL = (*sql.SQLService)(&c.common)
+	c.PPL = (*sql.PPLService)(&c.common)
+
+	c.AnomalyDetection = anomalyDetectionClient{
+		Detectors: (*anomalydetection.DetectorService)(&c.common),
+		Results:   (*anomalydetection.ResultService)(&c.common),
+	}
+
+	c.KNN = (*knn.KNNService)(&c.common)
+
+	paURL := config.PerformanceAnalyzerURL
+	if paURL == "" {
+		paURL = defaultPerformanceAnalyzerURL(baseURL)
+	}
+	pa := c.withBaseURL(paURL)
+
+	c.PerformanceAnalyzer = performanceAnalyzerClient{
+		Metrics: (*performanceanalyzer.MetricsService)(&pa.common),
+		RCA:     (*performanceanalyzer.RCAService)(&pa.common),
+	}
+
+	c.IndexManagement = indexManagementClient{
+		Rollups:    (*indexmanagement.RollupService)(&c.common),
+		Transforms: (*indexmanagement.TransformService)(&c.common),
+	}
+
+	if config.SniffInterval > 0 && c.pool != nil && !c.pool.prefixed {
+		go c.sniff(config.SniffInterval)
+	}
+
 	return c, nil
 }
 
+// Close stops the background sniffing of the nodes
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+// LimiterStats returns the stats of the rate limits by endpoint class, the stats of the limit of all
+// requests are returned as GlobalLimiter
+func (c *Client) LimiterStats() map[string]LimiterStats {
+	return c.limiters.stats()
+}
+
+// withBaseURL returns a copy of the client sending its requests to another base URL
+func (c *Client) withBaseURL(baseURL string) *Client {
+	clone := *c
+	clone.BaseURL = baseURL
+	clone.common.Client = &clone
+	clone.pool = nil
+
+	return &clone
+}
+
+func defaultPerformanceAnalyzerURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+
+	u.Host = net.JoinHostPort(u.Hostname(), "9600")
+	u.Path = ""
+
+	return u.String()
+}
+
 func (c *Client) GetBaseURL() string {
 	return c.BaseURL
 }
 
 func (c *Client) Do(ctx context.Context, reqBytes interface{}, endpoint string, method string) ([]byte, error) {
-	var req *http.Request
-	var err error
+	var body io.Reader
+
 	if reqBytes != nil {
 		_reqBytes, err := json.Marshal(reqBytes)
-
 		if err != nil {
 			return nil, err
 		}
 
-		req, err = http.NewRequest(method, c.common.Client.GetBaseURL()+endpoint, bytes.NewReader(_reqBytes))
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		req, err = http.NewRequest(method, c.common.Client.GetBaseURL()+endpoint, nil)
-		if err != nil {
-			return nil, err
-		}
+		body = bytes.NewBuffer(_reqBytes)
 	}
 
-	retryableReq, err := retryablehttp.NewRequest(req.Method, req.URL.String(), req.Body)
+	return c.DoRaw(ctx, body, "application/json", endpoint, method)
+}
+
+// DoRaw sends a request with a body which is already encoded, f.e. a NDJSON body of the bulk API. The body
+// has to be replayed for retries, a *bytes.Buffer is sent as it is while other readers are read into
+// memory first.
+func (c *Client) DoRaw(ctx context.Context, body io.Reader, contentType string, endpoint string, method string) ([]byte, error) {
+	statusCode, respBody, err := c.execute(ctx, body, contentType, endpoint, method)
 	if err != nil {
 		return nil, err
 	}
-	retryableReq.Header.Add("Content-Type", "application/json")
-	retryableReq.SetBasicAuth(c.Username, c.Password)
 
-	resp, err := c.Client.Do(retryableReq.WithContext(ctx))
-	if err != nil {
+	if err := c.info.unsupported(ctx, endpoint, statusCode, respBody); err != nil {
 		return nil, err
 	}
 
-	defer func() {
-		err := resp.Body.Close()
-		if err != nil {
-			log.Fatal(err)
+	if statusCode >= http.StatusBadRequest {
+		if re := common.NewResponseError(statusCode, respBody); re != nil {
+			return nil, re
 		}
-	}()
-
-	body, err := ioutil.ReadAll(resp.Body)
-
-	if err != nil {
-		return nil, err
 	}
 
-	switch resp.StatusCode {
+	switch statusCode {
 	case http.StatusNotFound:
 
 		var sr *common.StatusResponse
 
-		err = json.Unmarshal(body, &sr)
+		err = json.Unmarshal(respBody, &sr)
 		if err != nil {
 			return nil, err
 		}
@@ -168,10 +408,151 @@ func (c *Client) Do(ctx context.Context, reqBytes interface{}, endpoint string,
 			return nil, common.NewStatusError(*sr.Reason, *sr.InvalidKeys)
 		}
 	case http.StatusUnauthorized:
-		return nil, fmt.Errorf("unauthorized: %s", resp.Body)
+		return nil, fmt.Errorf("unauthorized: %s", respBody)
+	}
+
+	return respBody, nil
+}
+
+// Exists sends a HEAD request and reports whether the resource exists
+func (c *Client) Exists(ctx context.Context, path string) (bool, error) {
+	statusCode, _, err := c.execute(ctx, nil, "", path, http.MethodHead)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case statusCode == http.StatusNotFound:
+		return false, nil
+	case statusCode == http.StatusUnauthorized:
+		return false, fmt.Errorf("unauthorized: %s", path)
+	case statusCode >= http.StatusBadRequest:
+		return false, &common.ResponseError{StatusCode: statusCode, Reason: http.StatusText(statusCode)}
+	}
+
+	return true, nil
+}
+
+// execute sends a request and returns the status code and body of the response
+func (c *Client) execute(ctx context.Context, body io.Reader, contentType string, endpoint string, method string) (int, []byte, error) {
+	// the flavor is resolved before the limits are acquired, its detection sends a request itself
+	endpoint, err := c.pluginPath(ctx, endpoint)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	release, err := c.limiters.acquire(ctx, endpoint)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer release()
+
+	var rawBody interface{}
+	if body != nil {
+		rawBody = body
+	}
+
+	retryableReq, err := retryablehttp.NewRequest(method, c.common.Client.GetBaseURL()+endpoint, rawBody)
+	if err != nil {
+		return 0, nil, err
 	}
+	if contentType != "" {
+		retryableReq.Header.Add("Content-Type", contentType)
+	}
+	retryableReq.SetBasicAuth(c.Username, c.Password)
+
+	ctx, end := c.startRequest(ctx, &common.RequestInfo{
+		Method:    method,
+		Endpoint:  endpoint,
+		BytesSent: retryableReq.ContentLength,
+	})
 
-	return body, nil
+	statusCode, respBody, err := c.failover(ctx, retryableReq, endpoint)
+
+	end(&common.ResponseInfo{
+		StatusCode:    statusCode,
+		BytesReceived: int64(len(respBody)),
+		Body:          respBody,
+		Err:           err,
+	})
+
+	return statusCode, respBody, err
+}
+
+// failover sends a request to the nodes of the pool until a node responds
+func (c *Client) failover(ctx context.Context, retryableReq *retryablehttp.Request, endpoint string) (int, []byte, error) {
+	if c.pool == nil {
+		return c.send(ctx, retryableReq)
+	}
+
+	attempts := c.pool.size()
+
+	for attempt := 1; ; attempt++ {
+		baseURL := c.pool.pick()
+
+		u, err := url.Parse(baseURL + endpoint)
+		if err != nil {
+			return 0, nil, err
+		}
+		retryableReq.URL = u
+		retryableReq.Host = retryableReq.URL.Host
+
+		start := time.Now()
+
+		statusCode, respBody, err := c.send(ctx, retryableReq)
+		if err == nil {
+			c.pool.markAlive(baseURL, time.Since(start))
+			return statusCode, respBody, nil
+		}
+
+		// the node responded if the retries of error responses are exhausted, it is not dead
+		if ctx.Err() != nil || !connectionError(err) {
+			return 0, nil, err
+		}
+
+		c.pool.markDead(baseURL)
+
+		if attempt >= attempts {
+			return 0, nil, err
+		}
+	}
+}
+
+// connectionError reports whether a request failed without a response of the node, f.e. because the
+// connection was refused, reset or timed out
+func connectionError(err error) bool {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return false
+	}
+
+	var opErr *net.OpError
+
+	return errors.As(urlErr.Err, &opErr) || urlErr.Timeout() ||
+		errors.Is(urlErr.Err, io.EOF) || errors.Is(urlErr.Err, io.ErrUnexpectedEOF)
+}
+
+// send sends a request and reads the response
+func (c *Client) send(ctx context.Context, retryableReq *retryablehttp.Request) (int, []byte, error) {
+	resp, err := c.Client.Do(retryableReq.WithContext(ctx))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, respBody, nil
 }
 
 func (c *Client) Get(ctx context.Context, path string, T interface{}) error {
@@ -192,6 +573,19 @@ func (c *Client) Get(ctx context.Context, path string, T interface{}) error {
 	return nil
 }
 
+func (c *Client) Send(ctx context.Context, path string, method string, reqBytes interface{}, T interface{}) error {
+	body, err := c.Do(ctx, reqBytes, path, method)
+	if err != nil {
+		return err
+	}
+
+	if T == nil || len(body) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(body, T)
+}
+
 func (c *Client) Modify(ctx context.Context, path string, method string, reqBytes interface{}) error {
 	body, err := c.Do(ctx, reqBytes, path, method)
 	if err != nil {
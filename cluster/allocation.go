@@ -0,0 +1,95 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type AllocationService common.Service
+
+type AllocationServiceInterface interface {
+	Explain(ctx context.Context, request *AllocationExplainRequest) (*AllocationExplanation, error)
+}
+
+type AllocationExplainRequest struct {
+	Index       string `json:"index"`
+	Shard       int    `json:"shard"`
+	Primary     bool   `json:"primary"`
+	CurrentNode string `json:"current_node,omitempty"`
+}
+
+type AllocationExplanation struct {
+	Index                   string                   `json:"index"`
+	Shard                   int                      `json:"shard"`
+	Primary                 bool                     `json:"primary"`
+	CurrentState            string                   `json:"current_state"`
+	UnassignedInfo          *UnassignedInfo          `json:"unassigned_info,omitempty"`
+	CurrentNode             *AllocationNode          `json:"current_node,omitempty"`
+	CanAllocate             string                   `json:"can_allocate,omitempty"`
+	AllocateExplanation     string                   `json:"allocate_explanation,omitempty"`
+	CanRemainOnCurrentNode  string                   `json:"can_remain_on_current_node,omitempty"`
+	CanRebalanceCluster     string                   `json:"can_rebalance_cluster,omitempty"`
+	CanRebalanceToOtherNode string                   `json:"can_rebalance_to_other_node,omitempty"`
+	RebalanceExplanation    string                   `json:"rebalance_explanation,omitempty"`
+	CanRemainDecisions      []Decider                `json:"can_remain_decisions,omitempty"`
+	NodeAllocationDecisions []NodeAllocationDecision `json:"node_allocation_decisions,omitempty"`
+}
+
+type UnassignedInfo struct {
+	Reason               string `json:"reason"`
+	At                   string `json:"at"`
+	LastAllocationStatus string `json:"last_allocation_status"`
+	Details              string `json:"details,omitempty"`
+}
+
+type AllocationNode struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	TransportAddress string `json:"transport_address"`
+	WeightRanking    int    `json:"weight_ranking,omitempty"`
+}
+
+type NodeAllocationDecision struct {
+	NodeID           string    `json:"node_id"`
+	NodeName         string    `json:"node_name"`
+	TransportAddress string    `json:"transport_address"`
+	NodeDecision     string    `json:"node_decision"`
+	WeightRanking    int       `json:"weight_ranking"`
+	Deciders         []Decider `json:"deciders,omitempty"`
+}
+
+type Decider struct {
+	Decider     string `json:"decider"`
+	Decision    string `json:"decision"`
+	Explanation string `json:"explanation"`
+}
+
+// Explain why a shard is (un)assigned. Without request the first unassigned shard is explained, an error
+// is returned if there is none.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-allocation-explain.html
+func (s *AllocationService) Explain(ctx context.Context, request *AllocationExplainRequest) (*AllocationExplanation, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.allocation.explain")
+	defer done()
+
+	var explanation *AllocationExplanation
+	var err error
+
+	if request != nil {
+		err = s.Client.Send(ctx, common.AllocationExplainEndpoint, http.MethodPost, request, &explanation)
+	} else {
+		err = s.Client.Get(ctx, common.AllocationExplainEndpoint, &explanation)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return explanation, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type HealthService common.Service
+
+type HealthServiceInterface interface {
+	Get(ctx context.Context, options *HealthOptions) (*Health, error)
+}
+
+type HealthOptions struct {
+	// Indices restricts the health to the given indices
+	Indices []string
+
+	// Level is one of "cluster" (default), "indices" or "shards"
+	Level string
+
+	// WaitForStatus waits until the status is reached ("green", "yellow" or "red") or the timeout expires.
+	// Health.TimedOut is set if the timeout expired.
+	WaitForStatus             string
+	WaitForNodes              string
+	WaitForNoRelocatingShards bool
+	WaitForActiveShards       string
+	Timeout                   time.Duration
+}
+
+type Health struct {
+	ClusterName                 string                  `json:"cluster_name"`
+	Status                      string                  `json:"status"`
+	TimedOut                    bool                    `json:"timed_out"`
+	NumberOfNodes               int                     `json:"number_of_nodes"`
+	NumberOfDataNodes           int                     `json:"number_of_data_nodes"`
+	ActivePrimaryShards         int                     `json:"active_primary_shards"`
+	ActiveShards                int                     `json:"active_shards"`
+	RelocatingShards            int                     `json:"relocating_shards"`
+	InitializingShards          int                     `json:"initializing_shards"`
+	UnassignedShards            int                     `json:"unassigned_shards"`
+	DelayedUnassignedShards     int                     `json:"delayed_unassigned_shards"`
+	NumberOfPendingTasks        int                     `json:"number_of_pending_tasks"`
+	NumberOfInFlightFetch       int                     `json:"number_of_in_flight_fetch"`
+	TaskMaxWaitingInQueueMillis int64                   `json:"task_max_waiting_in_queue_millis"`
+	ActiveShardsPercent         float64                 `json:"active_shards_percent_as_number"`
+	Indices                     map[string]*IndexHealth `json:"indices,omitempty"`
+}
+
+type IndexHealth struct {
+	Status              string                  `json:"status"`
+	NumberOfShards      int                     `json:"number_of_shards"`
+	NumberOfReplicas    int                     `json:"number_of_replicas"`
+	ActivePrimaryShards int                     `json:"active_primary_shards"`
+	ActiveShards        int                     `json:"active_shards"`
+	RelocatingShards    int                     `json:"relocating_shards"`
+	InitializingShards  int                     `json:"initializing_shards"`
+	UnassignedShards    int                     `json:"unassigned_shards"`
+	Shards              map[string]*ShardHealth `json:"shards,omitempty"`
+}
+
+type ShardHealth struct {
+	Status             string `json:"status"`
+	PrimaryActive      bool   `json:"primary_active"`
+	ActiveShards       int    `json:"active_shards"`
+	RelocatingShards   int    `json:"relocating_shards"`
+	InitializingShards int    `json:"initializing_shards"`
+	UnassignedShards   int    `json:"unassigned_shards"`
+}
+
+// Get the health of the cluster, optionally waiting for a status
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-health.html
+func (s *HealthService) Get(ctx context.Context, options *HealthOptions) (*Health, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.health.get")
+	defer done()
+
+	endpoint := common.ClusterHealthEndpoint
+	params := url.Values{}
+
+	if options != nil {
+		if len(options.Indices) > 0 {
+			endpoint = endpoint + "/" + strings.Join(options.Indices, ",")
+		}
+		if options.Level != "" {
+			params.Set("level", options.Level)
+		}
+		if options.WaitForStatus != "" {
+			params.Set("wait_for_status", options.WaitForStatus)
+		}
+		if options.WaitForNodes != "" {
+			params.Set("wait_for_nodes", options.WaitForNodes)
+		}
+		if options.WaitForNoRelocatingShards {
+			params.Set("wait_for_no_relocating_shards", strconv.FormatBool(true))
+		}
+		if options.WaitForActiveShards != "" {
+			params.Set("wait_for_active_shards", options.WaitForActiveShards)
+		}
+		if options.Timeout > 0 {
+			params.Set("timeout", formatDuration(options.Timeout))
+		}
+	}
+
+	if len(params) > 0 {
+		endpoint = endpoint + "?" + params.Encode()
+	}
+
+	var health *Health
+
+	err := s.Client.Get(ctx, endpoint, &health)
+	if err != nil {
+		return nil, err
+	}
+
+	return health, nil
+}
+
+// formatDuration formats a duration as Elasticsearch time unit
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Nanoseconds()/int64(time.Millisecond))
+}
@@ -0,0 +1,189 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"strings"
+)
+
+type NodesService common.Service
+
+type NodesServiceInterface interface {
+	Info(ctx context.Context, nodeIDs ...string) (*NodesInfo, error)
+	Stats(ctx context.Context, nodeIDs ...string) (*NodesStats, error)
+	Cat(ctx context.Context) ([]CatNode, error)
+}
+
+type NodesInfo struct {
+	NodeCounts  NodeCounts           `json:"_nodes"`
+	ClusterName string               `json:"cluster_name"`
+	Nodes       map[string]*NodeInfo `json:"nodes"`
+}
+
+type NodeInfo struct {
+	Name             string            `json:"name"`
+	TransportAddress string            `json:"transport_address"`
+	Host             string            `json:"host"`
+	IP               string            `json:"ip"`
+	Version          string            `json:"version"`
+	BuildType        string            `json:"build_type"`
+	BuildHash        string            `json:"build_hash"`
+	Roles            []string          `json:"roles"`
+	Attributes       map[string]string `json:"attributes"`
+	HTTP             *HTTPInfo         `json:"http,omitempty"`
+	Plugins          []PluginInfo      `json:"plugins,omitempty"`
+	Modules          []PluginInfo      `json:"modules,omitempty"`
+}
+
+type HTTPInfo struct {
+	BoundAddress            []string `json:"bound_address"`
+	PublishAddress          string   `json:"publish_address"`
+	MaxContentLengthInBytes int64    `json:"max_content_length_in_bytes"`
+}
+
+type NodesStats struct {
+	NodeCounts  NodeCounts            `json:"_nodes"`
+	ClusterName string                `json:"cluster_name"`
+	Nodes       map[string]*NodeStats `json:"nodes"`
+}
+
+type NodeStats struct {
+	Name       string                      `json:"name"`
+	Host       string                      `json:"host"`
+	IP         string                      `json:"ip"`
+	Roles      []string                    `json:"roles"`
+	Timestamp  int64                       `json:"timestamp"`
+	Indices    NodeIndicesStats            `json:"indices"`
+	OS         OSStats                     `json:"os"`
+	JVM        JVMStats                    `json:"jvm"`
+	FS         NodeFSStats                 `json:"fs"`
+	ThreadPool map[string]*ThreadPoolStats `json:"thread_pool"`
+}
+
+type NodeIndicesStats struct {
+	Docs  DocsStats  `json:"docs"`
+	Store StoreStats `json:"store"`
+}
+
+type OSStats struct {
+	CPU OSCPUStats `json:"cpu"`
+	Mem OSMemStats `json:"mem"`
+}
+
+type OSCPUStats struct {
+	Percent int `json:"percent"`
+
+	// LoadAverage by "1m", "5m" and "15m"
+	LoadAverage map[string]float64 `json:"load_average"`
+}
+
+type OSMemStats struct {
+	TotalInBytes int64 `json:"total_in_bytes"`
+	FreeInBytes  int64 `json:"free_in_bytes"`
+	UsedInBytes  int64 `json:"used_in_bytes"`
+	UsedPercent  int   `json:"used_percent"`
+}
+
+type JVMStats struct {
+	UptimeInMillis int64       `json:"uptime_in_millis"`
+	Mem            JVMMemStats `json:"mem"`
+}
+
+type NodeFSStats struct {
+	Total FSStats `json:"total"`
+}
+
+type ThreadPoolStats struct {
+	Threads   int64 `json:"threads"`
+	Queue     int64 `json:"queue"`
+	Active    int64 `json:"active"`
+	Rejected  int64 `json:"rejected"`
+	Largest   int64 `json:"largest"`
+	Completed int64 `json:"completed"`
+}
+
+// CatNode is a row of the cat nodes API, all values are returned as strings
+type CatNode struct {
+	IP          string `json:"ip"`
+	HeapPercent string `json:"heap.percent"`
+	RAMPercent  string `json:"ram.percent"`
+	CPU         string `json:"cpu"`
+	Load1m      string `json:"load_1m"`
+	Load5m      string `json:"load_5m"`
+	Load15m     string `json:"load_15m"`
+	NodeRole    string `json:"node.role"`
+	Master      string `json:"master"`
+	Name        string `json:"name"`
+}
+
+// Info returns the information of all nodes or the nodes given by id
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-nodes-info.html
+func (s *NodesService) Info(ctx context.Context, nodeIDs ...string) (*NodesInfo, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.nodes.info")
+	defer done()
+
+	endpoint := common.NodesEndpoint
+
+	if len(nodeIDs) > 0 {
+		endpoint = endpoint + "/" + strings.Join(nodeIDs, ",")
+	}
+
+	var info *NodesInfo
+
+	err := s.Client.Get(ctx, endpoint, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// Stats returns the statistics of all nodes or the nodes given by id
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-nodes-stats.html
+func (s *NodesService) Stats(ctx context.Context, nodeIDs ...string) (*NodesStats, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.nodes.stats")
+	defer done()
+
+	endpoint := common.NodesEndpoint
+
+	if len(nodeIDs) > 0 {
+		endpoint = endpoint + "/" + strings.Join(nodeIDs, ",")
+	}
+
+	endpoint = endpoint + "/stats"
+
+	var stats *NodesStats
+
+	err := s.Client.Get(ctx, endpoint, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// Cat lists the nodes with their default cat columns
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cat-nodes.html
+func (s *NodesService) Cat(ctx context.Context) ([]CatNode, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.nodes.cat")
+	defer done()
+
+	endpoint := common.CatNodesEndpoint + "?format=json"
+
+	var nodes []CatNode
+
+	err := s.Client.Get(ctx, endpoint, &nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type SettingsService common.Service
+
+type SettingsServiceInterface interface {
+	Get(ctx context.Context, includeDefaults bool) (*Settings, error)
+	Put(ctx context.Context, settings *Settings) (*Settings, error)
+}
+
+// Settings by their flat key (f.e. "cluster.routing.allocation.enable"). Persistent settings survive a
+// full cluster restart, transient settings do not. A setting is reset by putting a nil value.
+type Settings struct {
+	Persistent map[string]interface{} `json:"persistent,omitempty"`
+	Transient  map[string]interface{} `json:"transient,omitempty"`
+	Defaults   map[string]interface{} `json:"defaults,omitempty"`
+}
+
+// Get the cluster settings, optionally including the defaults of all settings not set explicitly
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-get-settings.html
+func (s *SettingsService) Get(ctx context.Context, includeDefaults bool) (*Settings, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.settings.get")
+	defer done()
+
+	endpoint := common.ClusterSettingsEndpoint + "?flat_settings=true"
+
+	if includeDefaults {
+		endpoint = endpoint + "&include_defaults=true"
+	}
+
+	var settings *Settings
+
+	err := s.Client.Get(ctx, endpoint, &settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Put updates persistent and transient settings and returns the settings which were changed
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-update-settings.html
+func (s *SettingsService) Put(ctx context.Context, settings *Settings) (*Settings, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.settings.put")
+	defer done()
+
+	if settings == nil {
+		return nil, errors.New("settings are required")
+	}
+
+	endpoint := common.ClusterSettingsEndpoint + "?flat_settings=true"
+
+	request := &Settings{
+		Persistent: settings.Persistent,
+		Transient:  settings.Transient,
+	}
+
+	var response *Settings
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
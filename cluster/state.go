@@ -0,0 +1,73 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"strings"
+)
+
+type StateService common.Service
+
+type StateServiceInterface interface {
+	Get(ctx context.Context, metrics []string, indices []string) (*State, error)
+}
+
+// State of the cluster, only the parts selected by the metrics are set. The large parts are kept raw, they
+// can be decoded with json.Unmarshal.
+type State struct {
+	ClusterName  string                `json:"cluster_name"`
+	ClusterUUID  string                `json:"cluster_uuid"`
+	Version      int64                 `json:"version,omitempty"`
+	StateUUID    string                `json:"state_uuid,omitempty"`
+	MasterNode   string                `json:"master_node,omitempty"`
+	Blocks       json.RawMessage       `json:"blocks,omitempty"`
+	Nodes        map[string]*StateNode `json:"nodes,omitempty"`
+	Metadata     json.RawMessage       `json:"metadata,omitempty"`
+	RoutingTable json.RawMessage       `json:"routing_table,omitempty"`
+	RoutingNodes json.RawMessage       `json:"routing_nodes,omitempty"`
+}
+
+type StateNode struct {
+	Name             string            `json:"name"`
+	EphemeralID      string            `json:"ephemeral_id"`
+	TransportAddress string            `json:"transport_address"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+// Get the state of the cluster. Metrics select its parts (f.e. "metadata" or "routing_table", all parts
+// are returned without metrics) and indices restrict the metadata and routing table to the given indices.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-state.html
+func (s *StateService) Get(ctx context.Context, metrics []string, indices []string) (*State, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.state.get")
+	defer done()
+
+	endpoint := common.ClusterStateEndpoint
+
+	if len(metrics) > 0 || len(indices) > 0 {
+		metric := "_all"
+		if len(metrics) > 0 {
+			metric = strings.Join(metrics, ",")
+		}
+		endpoint = endpoint + "/" + metric
+	}
+	if len(indices) > 0 {
+		endpoint = endpoint + "/" + strings.Join(indices, ",")
+	}
+
+	var state *State
+
+	err := s.Client.Get(ctx, endpoint, &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
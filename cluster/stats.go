@@ -0,0 +1,109 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cluster
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+)
+
+type StatsService common.Service
+
+type StatsServiceInterface interface {
+	Get(ctx context.Context) (*Stats, error)
+}
+
+type Stats struct {
+	NodeCounts  NodeCounts          `json:"_nodes"`
+	ClusterName string              `json:"cluster_name"`
+	ClusterUUID string              `json:"cluster_uuid"`
+	Timestamp   int64               `json:"timestamp"`
+	Status      string              `json:"status"`
+	Indices     ClusterIndicesStats `json:"indices"`
+	Nodes       ClusterNodesStats   `json:"nodes"`
+}
+
+// NodeCounts is the number of nodes a request was executed on
+type NodeCounts struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+}
+
+type ClusterIndicesStats struct {
+	Count  int64       `json:"count"`
+	Shards ShardsStats `json:"shards"`
+	Docs   DocsStats   `json:"docs"`
+	Store  StoreStats  `json:"store"`
+}
+
+type ShardsStats struct {
+	Total       int64   `json:"total"`
+	Primaries   int64   `json:"primaries"`
+	Replication float64 `json:"replication"`
+}
+
+type DocsStats struct {
+	Count   int64 `json:"count"`
+	Deleted int64 `json:"deleted"`
+}
+
+type StoreStats struct {
+	SizeInBytes int64 `json:"size_in_bytes"`
+}
+
+type ClusterNodesStats struct {
+	// Count is the number of nodes in total and by role
+	Count    map[string]int  `json:"count"`
+	Versions []string        `json:"versions"`
+	JVM      ClusterJVMStats `json:"jvm"`
+	FS       FSStats         `json:"fs"`
+	Plugins  []PluginInfo    `json:"plugins"`
+}
+
+type ClusterJVMStats struct {
+	MaxUptimeInMillis int64       `json:"max_uptime_in_millis"`
+	Mem               JVMMemStats `json:"mem"`
+	Threads           int64       `json:"threads"`
+}
+
+type JVMMemStats struct {
+	HeapUsedInBytes int64 `json:"heap_used_in_bytes"`
+	HeapUsedPercent int   `json:"heap_used_percent,omitempty"`
+	HeapMaxInBytes  int64 `json:"heap_max_in_bytes"`
+}
+
+type FSStats struct {
+	TotalInBytes     int64 `json:"total_in_bytes"`
+	FreeInBytes      int64 `json:"free_in_bytes"`
+	AvailableInBytes int64 `json:"available_in_bytes"`
+}
+
+type PluginInfo struct {
+	Name                 string `json:"name"`
+	Version              string `json:"version"`
+	ElasticsearchVersion string `json:"elasticsearch_version"`
+	JavaVersion          string `json:"java_version"`
+	Description          string `json:"description"`
+	Classname            string `json:"classname"`
+}
+
+// Get the statistics of the whole cluster
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-stats.html
+func (s *StatsService) Get(ctx context.Context) (*Stats, error) {
+	ctx, done := s.Client.StartOperation(ctx, "cluster.stats.get")
+	defer done()
+
+	var stats *Stats
+
+	err := s.Client.Get(ctx, common.ClusterStatsEndpoint, &stats)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -7,6 +7,7 @@ package common
 
 import (
 	"context"
+	"io"
 )
 
 const (
@@ -16,6 +17,41 @@ const (
 	ActiongroupEndpoint  = "/_opendistro/_security/api/actiongroups/"
 	TenantEndpoint       = "/_opendistro/_security/api/tenants/"
 	HealthEndpoint       = "/_opendistro/_security/health"
+	SQLEndpoint          = "/_opendistro/_sql"
+	PPLEndpoint          = "/_opendistro/_ppl"
+
+	AnomalyDetectorsEndpoint      = "/_opendistro/_anomaly_detection/detectors/"
+	AnomalyDetectionStatsEndpoint = "/_opendistro/_anomaly_detection/stats"
+
+	KNNEndpoint = "/_opendistro/_knn"
+
+	PerformanceAnalyzerMetricsEndpoint = "/_opendistro/_performanceanalyzer/metrics"
+	PerformanceAnalyzerRCAEndpoint     = "/_opendistro/_performanceanalyzer/rca"
+
+	RollupsEndpoint    = "/_opendistro/_rollup/jobs/"
+	TransformsEndpoint = "/_opendistro/_transform/"
+
+	ClusterHealthEndpoint     = "/_cluster/health"
+	ClusterSettingsEndpoint   = "/_cluster/settings"
+	ClusterStatsEndpoint      = "/_cluster/stats"
+	ClusterStateEndpoint      = "/_cluster/state"
+	AllocationExplainEndpoint = "/_cluster/allocation/explain"
+	NodesEndpoint             = "/_nodes"
+	CatNodesEndpoint          = "/_cat/nodes"
+
+	AliasesEndpoint    = "/_aliases"
+	TemplatesEndpoint  = "/_template/"
+	CatIndicesEndpoint = "/_cat/indices"
+	CatPluginsEndpoint = "/_cat/plugins"
+
+	BulkEndpoint   = "/_bulk"
+	ScrollEndpoint = "/_search/scroll"
+	PitEndpoint    = "/_search/point_in_time"
+
+	SnapshotEndpoint = "/_snapshot/"
+
+	TasksEndpoint   = "/_tasks"
+	ReindexEndpoint = "/_reindex"
 )
 
 type Service struct {
@@ -30,9 +66,19 @@ type Patch struct {
 
 type ClientInterface interface {
 	Do(ctx context.Context, reqBytes interface{}, endpoint string, method string) ([]byte, error)
+	DoRaw(ctx context.Context, body io.Reader, contentType string, endpoint string, method string) ([]byte, error)
 	Get(ctx context.Context, path string, T interface{}) error
+	Exists(ctx context.Context, path string) (bool, error)
 	GetBaseURL() string
+	Send(ctx context.Context, path string, method string, reqBytes interface{}, T interface{}) error
 	Modify(ctx context.Context, path string, method string, reqBytes interface{}) error
+	StartOperation(ctx context.Context, operation string) (context.Context, func())
+}
+
+// DistributionClient is implemented by clients which report the distribution of the cluster, services use
+// it to reject APIs which are only available on OpenSearch
+type DistributionClient interface {
+	Distribution(ctx context.Context) (string, error)
 }
 
 type Modifyable interface {
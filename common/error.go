@@ -0,0 +1,105 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupported is wrapped by the errors of requests to endpoints which are not available on the
+// connected cluster, f.e. because a plugin is not installed. Check it with errors.Is.
+var ErrUnsupported = errors.New("not supported by the cluster")
+
+// ErrNotFound is wrapped by the errors of getting a resource which does not exist. Check it with
+// errors.Is or get the NotFoundError with errors.As.
+var ErrNotFound = errors.New("not found")
+
+// NotFoundError is returned for a resource which does not exist, f.e. a user
+type NotFoundError struct {
+	Resource string
+	Name     string
+}
+
+// ResponseError is returned for unsuccessful responses carrying an
+// Elasticsearch style error object, f.e. {"error":{"type":"...","reason":"..."},"status":400}
+type ResponseError struct {
+	StatusCode int
+	Type       string
+	Reason     string
+	Details    string
+	RootCause  []ErrorCause
+}
+
+type ErrorCause struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+type errorResponse struct {
+	Error json.RawMessage `json:"error"`
+}
+
+type errorObject struct {
+	ErrorCause
+	RootCause []ErrorCause `json:"root_cause"`
+}
+
+// NewResponseError parses an Elasticsearch style error body. It returns nil if the body does not contain
+// an error object, so callers can fall back to other error formats (f.e. the ones of the security plugin).
+func NewResponseError(statusCode int, body []byte) *ResponseError {
+	var er errorResponse
+
+	if err := json.Unmarshal(body, &er); err != nil || len(er.Error) == 0 {
+		return nil
+	}
+
+	var reason string
+	if err := json.Unmarshal(er.Error, &reason); err == nil {
+		return &ResponseError{StatusCode: statusCode, Reason: reason}
+	}
+
+	var eo errorObject
+	if err := json.Unmarshal(er.Error, &eo); err != nil {
+		return nil
+	}
+
+	return &ResponseError{
+		StatusCode: statusCode,
+		Type:       eo.Type,
+		Reason:     eo.Reason,
+		Details:    eo.Details,
+		RootCause:  eo.RootCause,
+	}
+}
+
+func (e *ResponseError) Error() string {
+	msg := fmt.Sprintf("%d", e.StatusCode)
+
+	if e.Type != "" {
+		msg = msg + " " + e.Type
+	}
+
+	if e.Reason != "" {
+		msg = msg + ": " + e.Reason
+	}
+
+	if e.Details != "" {
+		msg = msg + " (" + e.Details + ")"
+	}
+
+	return msg
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %q not found", e.Resource, e.Name)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"context"
+	"net/http"
+)
+
+type operationKey struct{}
+
+// Instrumentation observes the requests of a client, f.e. to collect metrics or to trace them
+type Instrumentation interface {
+	// StartOperation is called when a service method is called, the returned function is called when
+	// it returns. The requests sent by the method are started with the returned context.
+	StartOperation(ctx context.Context, operation *OperationInfo) (context.Context, func())
+
+	// StartRequest is called before a request is sent, the returned function is called with its result
+	// after all retries
+	StartRequest(ctx context.Context, request *RequestInfo) (context.Context, func(response *ResponseInfo))
+
+	// StartAttempt is called before every HTTP attempt of a request, attempt is 0 for the first one and
+	// counts the retries afterwards. The returned request is sent, so headers can be added to it.
+	StartAttempt(req *http.Request, attempt int) (*http.Request, func(resp *http.Response, err error))
+}
+
+// OperationInfo names a service method, Operation is the name used for requests (f.e.
+// "security.roles.create") and Func the name of the Go method (f.e. "RoleService.Create")
+type OperationInfo struct {
+	Operation string
+	Func      string
+}
+
+type RequestInfo struct {
+	// Operation is the service method which sent the request (f.e. "security.users.create"), it is empty
+	// for requests which were not sent by a service
+	Operation string
+	Method    string
+	Endpoint  string
+	BytesSent int64
+}
+
+// ResponseInfo is the result of a request, Err is set if no response was received
+type ResponseInfo struct {
+	StatusCode    int
+	BytesReceived int64
+	Body          []byte
+	Err           error
+}
+
+// WithOperation tags the requests sent with the context with the name of a service method
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// OperationFromContext returns the name of the service method set by WithOperation
+func OperationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+
+	return operation
+}
@@ -0,0 +1,452 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DocumentService common.Service
+
+type DocumentServiceInterface interface {
+	Index(ctx context.Context, index string, id string, document interface{}, options *WriteOptions) (*WriteResponse, error)
+	Get(ctx context.Context, index string, id string) (*GetResult, error)
+	Update(ctx context.Context, index string, id string, update *UpdateRequest, options *WriteOptions) (*WriteResponse, error)
+	Delete(ctx context.Context, index string, id string, options *WriteOptions) (*WriteResponse, error)
+	MGet(ctx context.Context, index string, ids ...string) ([]GetResult, error)
+	Search(ctx context.Context, query interface{}, indices ...string) (*SearchResponse, error)
+	Count(ctx context.Context, query interface{}, indices ...string) (int64, error)
+	DeleteByQuery(ctx context.Context, query interface{}, options *ByQueryOptions, indices ...string) (*ByQueryResponse, error)
+	UpdateByQuery(ctx context.Context, request interface{}, options *ByQueryOptions, indices ...string) (*ByQueryResponse, error)
+	Scroll(query interface{}, options *ScrollOptions, indices ...string) *ScrollIterator
+	Export(ctx context.Context, query interface{}, options *ExportOptions, handler func(ctx context.Context, hits []Hit) error, indices ...string) error
+	OpenPIT(ctx context.Context, keepAlive time.Duration, indices ...string) (string, error)
+	ClosePIT(ctx context.Context, ids ...string) error
+	SearchAfter(query interface{}, options *PITOptions, indices ...string) *PITIterator
+}
+
+// WriteOptions of a single document operation. If IfSeqNo and IfPrimaryTerm are set, the operation only
+// succeeds if the document has not been changed since (optimistic concurrency control), otherwise a
+// ResponseError with status 409 is returned.
+type WriteOptions struct {
+	IfSeqNo       *int64
+	IfPrimaryTerm *int64
+
+	// OpType "create" fails if the document exists already
+	OpType string
+
+	// Refresh is "true", "false" or "wait_for"
+	Refresh string
+	Routing string
+}
+
+type WriteResponse struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int64  `json:"_version"`
+	Result      string `json:"result"`
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+	Shards      Shards `json:"_shards"`
+}
+
+type Shards struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Skipped    int `json:"skipped,omitempty"`
+	Failed     int `json:"failed"`
+}
+
+// UpdateRequest updates a document partially by a doc or by a script
+type UpdateRequest struct {
+	Doc            interface{} `json:"doc,omitempty"`
+	DocAsUpsert    bool        `json:"doc_as_upsert,omitempty"`
+	Script         *Script     `json:"script,omitempty"`
+	ScriptedUpsert bool        `json:"scripted_upsert,omitempty"`
+	Upsert         interface{} `json:"upsert,omitempty"`
+}
+
+type Script struct {
+	Source string                 `json:"source,omitempty"`
+	ID     string                 `json:"id,omitempty"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+type GetResult struct {
+	Index       string          `json:"_index"`
+	ID          string          `json:"_id"`
+	Version     int64           `json:"_version"`
+	SeqNo       int64           `json:"_seq_no"`
+	PrimaryTerm int64           `json:"_primary_term"`
+	Found       bool            `json:"found"`
+	Source      json.RawMessage `json:"_source,omitempty"`
+}
+
+type SearchResponse struct {
+	Took         int64                      `json:"took"`
+	TimedOut     bool                       `json:"timed_out"`
+	Shards       Shards                     `json:"_shards"`
+	Hits         Hits                       `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations,omitempty"`
+	ScrollID     string                     `json:"_scroll_id,omitempty"`
+	PitID        string                     `json:"pit_id,omitempty"`
+}
+
+type Hits struct {
+	Total    Total    `json:"total"`
+	MaxScore *float64 `json:"max_score"`
+	Hits     []Hit    `json:"hits"`
+}
+
+type Total struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+type Hit struct {
+	Index       string              `json:"_index"`
+	ID          string              `json:"_id"`
+	Score       *float64            `json:"_score"`
+	Version     int64               `json:"_version,omitempty"`
+	SeqNo       int64               `json:"_seq_no,omitempty"`
+	PrimaryTerm int64               `json:"_primary_term,omitempty"`
+	Source      json.RawMessage     `json:"_source,omitempty"`
+	Sort        []interface{}       `json:"sort,omitempty"`
+	Highlight   map[string][]string `json:"highlight,omitempty"`
+}
+
+// ByQueryOptions of delete and update by query. With WaitForCompletion set to false the operation runs
+// as task and only the task id is returned.
+type ByQueryOptions struct {
+	WaitForCompletion *bool
+
+	// Conflicts "proceed" continues on version conflicts instead of aborting
+	Conflicts         string
+	Refresh           bool
+	Slices            string
+	RequestsPerSecond float64
+	Routing           string
+}
+
+type ByQueryResponse struct {
+	Took              int64             `json:"took"`
+	TimedOut          bool              `json:"timed_out"`
+	Total             int64             `json:"total"`
+	Created           int64             `json:"created,omitempty"`
+	Updated           int64             `json:"updated"`
+	Deleted           int64             `json:"deleted"`
+	Batches           int64             `json:"batches"`
+	VersionConflicts  int64             `json:"version_conflicts"`
+	Noops             int64             `json:"noops"`
+	Retries           Retries           `json:"retries"`
+	ThrottledMillis   int64             `json:"throttled_millis"`
+	RequestsPerSecond float64           `json:"requests_per_second"`
+	Failures          []json.RawMessage `json:"failures,omitempty"`
+
+	// Task is the id of the task if the operation does not wait for completion
+	Task string `json:"task,omitempty"`
+}
+
+type Retries struct {
+	Bulk   int64 `json:"bulk"`
+	Search int64 `json:"search"`
+}
+
+type mgetRequest struct {
+	IDs []string `json:"ids"`
+}
+
+type mgetResponse struct {
+	Docs []GetResult `json:"docs"`
+}
+
+type countResponse struct {
+	Count int64 `json:"count"`
+}
+
+// Decode unmarshals the source of the document
+func (r *GetResult) Decode(v interface{}) error {
+	return json.Unmarshal(r.Source, v)
+}
+
+// Decode unmarshals the source of the hit
+func (h *Hit) Decode(v interface{}) error {
+	return json.Unmarshal(h.Source, v)
+}
+
+// Decode unmarshals the sources of all hits into a pointer to a slice, f.e. *[]Book
+func (h *Hits) Decode(v interface{}) error {
+	sources := make([]json.RawMessage, len(h.Hits))
+
+	for i, hit := range h.Hits {
+		sources[i] = hit.Source
+	}
+
+	b, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// Index a document. Without id the document is added with an id assigned by Elasticsearch.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-index_.html
+func (s *DocumentService) Index(ctx context.Context, index string, id string, document interface{}, options *WriteOptions) (*WriteResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.index")
+	defer done()
+
+	endpoint := "/" + index + "/_doc"
+	method := http.MethodPost
+
+	if id != "" {
+		endpoint = endpoint + "/" + url.PathEscape(id)
+		method = http.MethodPut
+	}
+
+	var response *WriteResponse
+
+	err := s.Client.Send(ctx, endpoint+options.query(), method, document, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Get a document by id. If the document does not exist, the result is returned with Found set to false.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-get.html
+func (s *DocumentService) Get(ctx context.Context, index string, id string) (*GetResult, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.get")
+	defer done()
+
+	endpoint := "/" + index + "/_doc/" + url.PathEscape(id)
+
+	var result *GetResult
+
+	err := s.Client.Get(ctx, endpoint, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Update a document partially
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-update.html
+func (s *DocumentService) Update(ctx context.Context, index string, id string, update *UpdateRequest, options *WriteOptions) (*WriteResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.update")
+	defer done()
+
+	endpoint := "/" + index + "/_update/" + url.PathEscape(id) + options.query()
+
+	var response *WriteResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, update, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Delete a document by id. If the document does not exist, the result is "not_found".
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-delete.html
+func (s *DocumentService) Delete(ctx context.Context, index string, id string, options *WriteOptions) (*WriteResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.delete")
+	defer done()
+
+	endpoint := "/" + index + "/_doc/" + url.PathEscape(id) + options.query()
+
+	var response *WriteResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodDelete, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// MGet gets multiple documents of an index by id, in the order of the ids
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-multi-get.html
+func (s *DocumentService) MGet(ctx context.Context, index string, ids ...string) ([]GetResult, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.mget")
+	defer done()
+
+	endpoint := "/" + index + "/_mget"
+
+	var response *mgetResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, &mgetRequest{IDs: ids}, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Docs, nil
+}
+
+// Search indices (or all indices if none is given) with a request body, f.e. {"query":{"match_all":{}}}
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/search-search.html
+func (s *DocumentService) Search(ctx context.Context, query interface{}, indices ...string) (*SearchResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.search")
+	defer done()
+
+	endpoint := indicesPath(indices) + "/_search"
+
+	var response *SearchResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, query, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Count the documents of indices (or all indices if none is given) matching a query, f.e.
+// {"query":{"term":{"user":"kirk"}}}. All documents are counted if the query is nil.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/search-count.html
+func (s *DocumentService) Count(ctx context.Context, query interface{}, indices ...string) (int64, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.count")
+	defer done()
+
+	endpoint := indicesPath(indices) + "/_count"
+
+	var response *countResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, query, &response)
+	if err != nil || response == nil {
+		return 0, err
+	}
+
+	return response.Count, nil
+}
+
+// DeleteByQuery deletes the documents matching a query, f.e. {"query":{"range":{"age":{"gt":30}}}}
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-delete-by-query.html
+func (s *DocumentService) DeleteByQuery(ctx context.Context, query interface{}, options *ByQueryOptions, indices ...string) (*ByQueryResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.delete_by_query")
+	defer done()
+
+	endpoint := indicesPath(indices) + "/_delete_by_query" + options.query()
+
+	var response *ByQueryResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, query, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// UpdateByQuery updates the documents matching the query of the request by its script. Without request
+// all documents are reindexed in place, f.e. to pick up mapping changes.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-update-by-query.html
+func (s *DocumentService) UpdateByQuery(ctx context.Context, request interface{}, options *ByQueryOptions, indices ...string) (*ByQueryResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.update_by_query")
+	defer done()
+
+	endpoint := indicesPath(indices) + "/_update_by_query" + options.query()
+
+	var response *ByQueryResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (o *WriteOptions) query() string {
+	if o == nil {
+		return ""
+	}
+
+	params := url.Values{}
+
+	if o.IfSeqNo != nil {
+		params.Set("if_seq_no", strconv.FormatInt(*o.IfSeqNo, 10))
+	}
+	if o.IfPrimaryTerm != nil {
+		params.Set("if_primary_term", strconv.FormatInt(*o.IfPrimaryTerm, 10))
+	}
+	if o.OpType != "" {
+		params.Set("op_type", o.OpType)
+	}
+	if o.Refresh != "" {
+		params.Set("refresh", o.Refresh)
+	}
+	if o.Routing != "" {
+		params.Set("routing", o.Routing)
+	}
+
+	return encode(params)
+}
+
+func (o *ByQueryOptions) query() string {
+	if o == nil {
+		return ""
+	}
+
+	params := url.Values{}
+
+	if o.WaitForCompletion != nil {
+		params.Set("wait_for_completion", strconv.FormatBool(*o.WaitForCompletion))
+	}
+	if o.Conflicts != "" {
+		params.Set("conflicts", o.Conflicts)
+	}
+	if o.Refresh {
+		params.Set("refresh", "true")
+	}
+	if o.Slices != "" {
+		params.Set("slices", o.Slices)
+	}
+	if o.RequestsPerSecond != 0 {
+		params.Set("requests_per_second", strconv.FormatFloat(o.RequestsPerSecond, 'f', -1, 64))
+	}
+	if o.Routing != "" {
+		params.Set("routing", o.Routing)
+	}
+
+	return encode(params)
+}
+
+func encode(params url.Values) string {
+	if len(params) == 0 {
+		return ""
+	}
+
+	return "?" + params.Encode()
+}
+
+func indicesPath(indices []string) string {
+	if len(indices) == 0 {
+		return ""
+	}
+
+	return "/" + strings.Join(indices, ",")
+}
@@ -0,0 +1,241 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPITKeepAlive is the time a point in time is kept alive between two pages
+const DefaultPITKeepAlive = time.Minute
+
+// PITOptions of a search after iteration over a point in time
+type PITOptions struct {
+	// KeepAlive of the point in time between two pages, defaults to DefaultPITKeepAlive
+	KeepAlive time.Duration
+
+	// Size is the number of hits per page, overrides the size of the query
+	Size int
+
+	// Sort of the hits, it has to end with a unique field as tiebreaker (f.e. a unique keyword field or
+	// "_shard_doc" on versions supporting it). It defaults to the sort of the query, one of them is
+	// required.
+	Sort []interface{}
+}
+
+// PITIterator pages through the hits of a search with search_after on a point in time, unlike a scroll
+// the point in time is a consistent view which can be searched by several requests. It has to be closed
+// to release the point in time. Points in time are only supported by OpenSearch, an error wrapping
+// common.ErrUnsupported is returned by Open Distro.
+type PITIterator struct {
+	service *DocumentService
+	query   interface{}
+	options PITOptions
+	indices []string
+
+	pitID       string
+	searchAfter []interface{}
+	total       *Total
+	done        bool
+}
+
+type openPITResponse struct {
+	PitID string `json:"pit_id"`
+}
+
+type closePITRequest struct {
+	PitID []string `json:"pit_id"`
+}
+
+type pitRequest struct {
+	ID        string `json:"id"`
+	KeepAlive string `json:"keep_alive"`
+}
+
+// OpenPIT creates a point in time of indices and returns its id. Points in time are only supported by
+// OpenSearch.
+//
+// see: https://opensearch.org/docs/latest/search-plugins/point-in-time-api/
+func (s *DocumentService) OpenPIT(ctx context.Context, keepAlive time.Duration, indices ...string) (string, error) {
+	ctx, done := s.Client.StartOperation(ctx, "documents.open_pit")
+	defer done()
+
+	if len(indices) == 0 {
+		return "", errors.New("point in time requires at least one index")
+	}
+
+	if err := s.requireOpenSearch(ctx); err != nil {
+		return "", err
+	}
+
+	endpoint := "/" + strings.Join(indices, ",") + common.PitEndpoint + "?keep_alive=" + formatDuration(keepAlive)
+
+	var response *openPITResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, nil, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if response == nil || response.PitID == "" {
+		return "", errors.New("point in time did not return an id")
+	}
+
+	return response.PitID, nil
+}
+
+// ClosePIT deletes points in time by id
+//
+// see: https://opensearch.org/docs/latest/search-plugins/point-in-time-api/
+func (s *DocumentService) ClosePIT(ctx context.Context, ids ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "documents.close_pit")
+	defer done()
+
+	if err := s.requireOpenSearch(ctx); err != nil {
+		return err
+	}
+
+	_, err := s.Client.Do(ctx, &closePITRequest{PitID: ids}, common.PitEndpoint, http.MethodDelete)
+
+	return err
+}
+
+// SearchAfter returns an iterator over all hits of a search on a point in time of the indices, the point
+// in time is opened with the first call of Next
+//
+// see: https://opensearch.org/docs/latest/search-plugins/searching-data/paginate/#the-search_after-parameter
+func (s *DocumentService) SearchAfter(query interface{}, options *PITOptions, indices ...string) *PITIterator {
+	it := &PITIterator{
+		service: s,
+		query:   query,
+		indices: indices,
+	}
+
+	if options != nil {
+		it.options = *options
+	}
+	if it.options.KeepAlive <= 0 {
+		it.options.KeepAlive = DefaultPITKeepAlive
+	}
+
+	return it
+}
+
+// Next returns the next page of hits, io.EOF is returned after the last page
+func (it *PITIterator) Next(ctx context.Context) ([]Hit, error) {
+	ctx, done := it.service.Client.StartOperation(ctx, "documents.search_after")
+	defer done()
+
+	if it.done {
+		return nil, io.EOF
+	}
+
+	request, err := searchBody(it.query)
+	if err != nil {
+		return nil, err
+	}
+
+	// there is no default sort, _id requires fielddata and _shard_doc is not supported by all versions
+	if len(it.options.Sort) > 0 {
+		request["sort"] = it.options.Sort
+	} else if _, ok := request["sort"]; !ok {
+		return nil, errors.New("search after requires a sort with a unique tiebreaker field")
+	}
+
+	if it.pitID == "" {
+		pitID, err := it.service.OpenPIT(ctx, it.options.KeepAlive, it.indices...)
+		if err != nil {
+			return nil, err
+		}
+		it.pitID = pitID
+	}
+
+	// the indices are part of the point in time, the search must not name them
+	request["pit"] = &pitRequest{ID: it.pitID, KeepAlive: formatDuration(it.options.KeepAlive)}
+
+	if it.options.Size > 0 {
+		request["size"] = it.options.Size
+	}
+	if it.searchAfter != nil {
+		request["search_after"] = it.searchAfter
+	}
+
+	var response *SearchResponse
+
+	err = it.service.Client.Send(ctx, "/_search", http.MethodPost, request, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		it.done = true
+		return nil, io.EOF
+	}
+
+	// the id of a point in time may change with every search
+	if response.PitID != "" {
+		it.pitID = response.PitID
+	}
+	if it.total == nil {
+		total := response.Hits.Total
+		it.total = &total
+	}
+
+	hits := response.Hits.Hits
+	if len(hits) == 0 {
+		it.done = true
+		return nil, io.EOF
+	}
+
+	it.searchAfter = hits[len(hits)-1].Sort
+
+	return hits, nil
+}
+
+// requireOpenSearch returns an error wrapping common.ErrUnsupported if the cluster is not OpenSearch,
+// Open Distro responds to the point in time API with a bare 404
+func (s *DocumentService) requireOpenSearch(ctx context.Context) error {
+	client, ok := s.Client.(common.DistributionClient)
+	if !ok {
+		return nil
+	}
+
+	distribution, err := client.Distribution(ctx)
+	if err != nil {
+		return err
+	}
+
+	if distribution != "opensearch" {
+		return fmt.Errorf("point in time is only supported by OpenSearch, not by %s: %w", distribution, common.ErrUnsupported)
+	}
+
+	return nil
+}
+
+// Total returns the total number of hits, it is nil until the first page was fetched
+func (it *PITIterator) Total() *Total {
+	return it.total
+}
+
+// Close deletes the point in time, it is safe to call Close more than once
+func (it *PITIterator) Close(ctx context.Context) error {
+	it.done = true
+
+	if it.pitID == "" {
+		return nil
+	}
+
+	pitID := it.pitID
+	it.pitID = ""
+
+	return it.service.ClosePIT(ctx, pitID)
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"github.com/WhizUs/go-opendistro/document"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePIT emulates the root endpoint and the point in time API of a cluster with five documents
+type fakePIT struct {
+	mu           sync.Mutex
+	distribution string
+	opened       []string
+	closed       []string
+	searches     []map[string]interface{}
+}
+
+func (f *fakePIT) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprintf(w, `{"name":"node-1","version":{"distribution":%q,"number":"2.11.0"}}`, f.distribution)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/books/_search/point_in_time":
+		f.opened = append(f.opened, r.URL.Query().Get("keep_alive"))
+		fmt.Fprint(w, `{"pit_id":"pit-1"}`)
+
+	case r.Method == http.MethodDelete && r.URL.Path == "/_search/point_in_time":
+		var request struct {
+			PitID []string `json:"pit_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		f.closed = append(f.closed, request.PitID...)
+		fmt.Fprint(w, `{"pits":[]}`)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/_search":
+		var request map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		f.searches = append(f.searches, request)
+
+		// two hits per page, sorted by the id
+		start := 0
+		if after, ok := request["search_after"].([]interface{}); ok {
+			start = int(after[0].(float64))
+		}
+
+		var hits []string
+		for id := start + 1; id <= start+2 && id <= 5; id++ {
+			hits = append(hits, fmt.Sprintf(`{"_index":"books","_id":"%d","_source":{"title":"book %d"},"sort":[%d]}`, id, id, id))
+		}
+
+		fmt.Fprintf(w, `{"pit_id":"pit-%d","hits":{"total":{"value":5,"relation":"eq"},"hits":[%s]}}`, len(f.searches)+1, strings.Join(hits, ","))
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+var sortByID = &document.PITOptions{Sort: []interface{}{map[string]string{"id": "asc"}}, Size: 2}
+
+func TestSearchAfter(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakePIT{distribution: "opensearch"}
+	client := newClient(t, fake)
+
+	it := client.Documents.SearchAfter(map[string]interface{}{"query": map[string]interface{}{"match_all": struct{}{}}}, sortByID, "books")
+
+	var ids []string
+	for {
+		hits, err := it.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next: %s", err)
+		}
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+	}
+
+	if err := it.Close(ctx); err != nil {
+		t.Fatalf("close: %s", err)
+	}
+
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3", "4", "5"}) {
+		t.Errorf("ids = %v", ids)
+	}
+	if it.Total() == nil || it.Total().Value != 5 {
+		t.Errorf("total = %+v", it.Total())
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if !reflect.DeepEqual(fake.opened, []string{"60000ms"}) {
+		t.Errorf("opened = %v, want one point in time kept alive for a minute", fake.opened)
+	}
+
+	// every search uses the id returned by the previous one
+	for n, search := range fake.searches {
+		pit := search["pit"].(map[string]interface{})
+		if want := fmt.Sprintf("pit-%d", n+1); pit["id"] != want {
+			t.Errorf("search %d uses point in time %v, want %s", n, pit["id"], want)
+		}
+		if search["size"] != float64(2) || search["sort"] == nil {
+			t.Errorf("search %d = %v", n, search)
+		}
+	}
+
+	if want := []string{fmt.Sprintf("pit-%d", len(fake.searches)+1)}; !reflect.DeepEqual(fake.closed, want) {
+		t.Errorf("closed = %v, want %v", fake.closed, want)
+	}
+}
+
+func TestSearchAfterUnsupportedByOpenDistro(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakePIT{}
+	client := newClient(t, fake)
+
+	_, err := client.Documents.SearchAfter(nil, sortByID, "books").Next(ctx)
+	if !errors.Is(err, common.ErrUnsupported) {
+		t.Errorf("next = %v, want %v", err, common.ErrUnsupported)
+	}
+
+	_, err = client.Documents.OpenPIT(ctx, 0, "books")
+	if !errors.Is(err, common.ErrUnsupported) {
+		t.Errorf("open = %v, want %v", err, common.ErrUnsupported)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if len(fake.opened) != 0 {
+		t.Errorf("opened = %v, want none", fake.opened)
+	}
+}
+
+func TestSearchAfterRequiresSort(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakePIT{distribution: "opensearch"}
+	client := newClient(t, fake)
+
+	if _, err := client.Documents.SearchAfter(nil, nil, "books").Next(ctx); err == nil {
+		t.Error("next without sort returned no error")
+	}
+
+	// the sort of the query is used if the options have none
+	query := map[string]interface{}{"sort": []interface{}{map[string]string{"id": "asc"}}}
+	it := client.Documents.SearchAfter(query, nil, "books")
+	defer it.Close(ctx)
+
+	if _, err := it.Next(ctx); err != nil {
+		t.Errorf("next with the sort of the query: %s", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	if len(fake.opened) != 1 {
+		t.Errorf("opened = %v, want the point in time of the search with sort only", fake.opened)
+	}
+}
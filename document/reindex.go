@@ -0,0 +1,262 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"github.com/WhizUs/go-opendistro/tasks"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultReindexPollInterval is the interval the progress of a reindex task is polled with
+const DefaultReindexPollInterval = 5 * time.Second
+
+type ReindexService common.Service
+
+type ReindexServiceInterface interface {
+	Reindex(ctx context.Context, request *ReindexRequest, options *ReindexOptions) (*ByQueryResponse, error)
+	Start(ctx context.Context, request *ReindexRequest, options *ReindexOptions) (string, error)
+	Rethrottle(ctx context.Context, taskID string, requestsPerSecond float64) error
+}
+
+type ReindexRequest struct {
+	// Conflicts "proceed" continues on version conflicts instead of aborting
+	Conflicts string        `json:"conflicts,omitempty"`
+	MaxDocs   int64         `json:"max_docs,omitempty"`
+	Source    ReindexSource `json:"source"`
+	Dest      ReindexDest   `json:"dest"`
+	Script    *Script       `json:"script,omitempty"`
+}
+
+type ReindexSource struct {
+	Index []string `json:"index"`
+
+	// Query filters the documents to reindex, f.e. {"term":{"user":"kirk"}}
+	Query  interface{}   `json:"query,omitempty"`
+	Remote *RemoteSource `json:"remote,omitempty"`
+
+	// Size is the batch size
+	Size   int      `json:"size,omitempty"`
+	Fields []string `json:"_source,omitempty"`
+	Slice  *Slice   `json:"slice,omitempty"`
+}
+
+// RemoteSource reindexes from a remote cluster, its host has to be listed in reindex.remote.whitelist
+// of the local cluster
+type RemoteSource struct {
+	Host           string            `json:"host"`
+	Username       string            `json:"username,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	SocketTimeout  string            `json:"socket_timeout,omitempty"`
+	ConnectTimeout string            `json:"connect_timeout,omitempty"`
+}
+
+type ReindexDest struct {
+	Index string `json:"index"`
+
+	// OpType "create" only creates documents missing in the destination
+	OpType      string `json:"op_type,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+	Pipeline    string `json:"pipeline,omitempty"`
+	Routing     string `json:"routing,omitempty"`
+}
+
+type ReindexOptions struct {
+	// Slices is the number of slices or "auto"
+	Slices string
+
+	// RequestsPerSecond throttles the reindex, -1 disables throttling
+	RequestsPerSecond float64
+	Refresh           bool
+
+	// PollInterval of the progress, defaults to DefaultReindexPollInterval
+	PollInterval time.Duration
+
+	// OnProgress is called with the status of the reindex task on every poll
+	OnProgress func(progress *Progress)
+
+	// Progress receives the status of the reindex task on every poll like OnProgress. A status is dropped
+	// if the channel is not ready to receive it, so a slow receiver does not delay the reindex. The
+	// channel is not closed.
+	Progress chan<- *Progress
+}
+
+// Progress is the status of a running reindex, update by query or delete by query task
+type Progress struct {
+	Total                int64   `json:"total"`
+	Created              int64   `json:"created"`
+	Updated              int64   `json:"updated"`
+	Deleted              int64   `json:"deleted"`
+	Batches              int64   `json:"batches"`
+	VersionConflicts     int64   `json:"version_conflicts"`
+	Noops                int64   `json:"noops"`
+	Retries              Retries `json:"retries"`
+	ThrottledMillis      int64   `json:"throttled_millis"`
+	RequestsPerSecond    float64 `json:"requests_per_second"`
+	ThrottledUntilMillis int64   `json:"throttled_until_millis"`
+}
+
+type taskResponse struct {
+	Task string `json:"task"`
+}
+
+// Reindex copies documents from a source to a destination index and blocks until it is completed. The
+// reindex runs as task which is polled for its progress and canceled if the context is done.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-reindex.html
+func (s *ReindexService) Reindex(ctx context.Context, request *ReindexRequest, options *ReindexOptions) (*ByQueryResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "reindex.reindex")
+	defer done()
+
+	taskID, err := s.Start(ctx, request, options)
+	if err != nil {
+		return nil, err
+	}
+
+	pollInterval := DefaultReindexPollInterval
+	if options != nil && options.PollInterval > 0 {
+		pollInterval = options.PollInterval
+	}
+
+	ts := (*tasks.TaskService)(s)
+
+	for {
+		result, err := ts.Get(ctx, taskID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, s.cancel(taskID, ctx.Err())
+			}
+			return nil, err
+		}
+
+		if options != nil {
+			options.report(result.Task.Status)
+		}
+
+		if result.Completed {
+			if result.Error != nil {
+				return nil, result.Error
+			}
+
+			var response *ByQueryResponse
+
+			err = result.Decode(&response)
+			if err != nil {
+				return nil, err
+			}
+
+			return response, nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, s.cancel(taskID, ctx.Err())
+		}
+	}
+}
+
+// Start a reindex without waiting for its completion and return the id of its task
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-reindex.html
+func (s *ReindexService) Start(ctx context.Context, request *ReindexRequest, options *ReindexOptions) (string, error) {
+	ctx, done := s.Client.StartOperation(ctx, "reindex.start")
+	defer done()
+
+	params := url.Values{}
+	params.Set("wait_for_completion", "false")
+
+	if options != nil {
+		if options.Slices != "" {
+			params.Set("slices", options.Slices)
+		}
+		if options.RequestsPerSecond != 0 {
+			params.Set("requests_per_second", strconv.FormatFloat(options.RequestsPerSecond, 'f', -1, 64))
+		}
+		if options.Refresh {
+			params.Set("refresh", "true")
+		}
+	}
+
+	endpoint := common.ReindexEndpoint + "?" + params.Encode()
+
+	var response *taskResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, request, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if response == nil || response.Task == "" {
+		return "", errors.New("reindex did not return a task")
+	}
+
+	return response.Task, nil
+}
+
+// Rethrottle changes the requests per second of a running reindex, -1 disables throttling
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-reindex.html#docs-reindex-rethrottle
+func (s *ReindexService) Rethrottle(ctx context.Context, taskID string, requestsPerSecond float64) error {
+	ctx, done := s.Client.StartOperation(ctx, "reindex.rethrottle")
+	defer done()
+
+	endpoint := common.ReindexEndpoint + "/" + taskID + "/_rethrottle?requests_per_second=" +
+		strconv.FormatFloat(requestsPerSecond, 'f', -1, 64)
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// cancel the task of a reindex whose caller is gone, the context of the caller is done already. The cause
+// is returned, a failure of the cancellation is attached to it.
+func (s *ReindexService) cancel(taskID string, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err := (*tasks.TaskService)(s).Cancel(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("%w (canceling reindex task %s failed: %s)", cause, taskID, err)
+	}
+
+	return cause
+}
+
+// report passes the status of a reindex task to the progress callback and channel
+func (o *ReindexOptions) report(status json.RawMessage) {
+	if (o.OnProgress == nil && o.Progress == nil) || len(status) == 0 {
+		return
+	}
+
+	var progress *Progress
+
+	if err := json.Unmarshal(status, &progress); err != nil || progress == nil {
+		return
+	}
+
+	if o.OnProgress != nil {
+		o.OnProgress(progress)
+	}
+
+	if o.Progress != nil {
+		// the receiver gets its own copy, the callback might keep or modify the progress
+		p := *progress
+
+		select {
+		case o.Progress <- &p:
+		default:
+		}
+	}
+}
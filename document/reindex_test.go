@@ -0,0 +1,138 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/document"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReindex emulates a reindex task of ten documents which completes after the given number of polls
+type fakeReindex struct {
+	mu         sync.Mutex
+	polls      int
+	completeAt int
+	cancelFail bool
+	canceled   int
+}
+
+func (f *fakeReindex) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/_reindex":
+		fmt.Fprint(w, `{"task":"node-1:7"}`)
+
+	case r.Method == http.MethodGet && r.URL.Path == "/_tasks/node-1:7":
+		f.polls++
+		created := f.polls * 5
+		if f.completeAt > 0 && f.polls >= f.completeAt {
+			fmt.Fprintf(w, `{"completed":true,"task":{"node":"node-1","id":7,"status":{"total":10,"created":10}},`+
+				`"response":{"total":10,"created":10}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"completed":false,"task":{"node":"node-1","id":7,"status":{"total":10,"created":%d}}}`, created)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/_tasks/node-1:7/_cancel":
+		f.canceled++
+		if f.cancelFail {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"type":"illegal_argument_exception","reason":"task is not cancellable"},"status":400}`)
+			return
+		}
+		fmt.Fprint(w, `{"nodes":{}}`)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestReindexProgress(t *testing.T) {
+	client := newClient(t, &fakeReindex{completeAt: 3})
+
+	progress := make(chan *document.Progress, 10)
+
+	var reported []int64
+
+	response, err := client.Reindex.Reindex(context.Background(), &document.ReindexRequest{
+		Source: document.ReindexSource{Index: []string{"books"}},
+		Dest:   document.ReindexDest{Index: "books-v2"},
+	}, &document.ReindexOptions{
+		PollInterval: time.Millisecond,
+		OnProgress:   func(p *document.Progress) { reported = append(reported, p.Created) },
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("reindex: %s", err)
+	}
+	if response.Created != 10 {
+		t.Errorf("response = %+v", response)
+	}
+
+	close(progress)
+
+	var received []int64
+	for p := range progress {
+		received = append(received, p.Created)
+	}
+
+	want := "[5 10 10]"
+	if fmt.Sprint(reported) != want || fmt.Sprint(received) != want {
+		t.Errorf("reported %v and received %v, want %s", reported, received, want)
+	}
+}
+
+func TestReindexProgressDoesNotBlock(t *testing.T) {
+	client := newClient(t, &fakeReindex{completeAt: 5})
+
+	// nobody receives, every status is dropped
+	progress := make(chan *document.Progress)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.Reindex.Reindex(ctx, &document.ReindexRequest{}, &document.ReindexOptions{
+		PollInterval: time.Millisecond,
+		Progress:     progress,
+	})
+	if err != nil {
+		t.Fatalf("reindex: %s", err)
+	}
+}
+
+func TestReindexCanceled(t *testing.T) {
+	for _, cancelFail := range []bool{false, true} {
+		fake := &fakeReindex{cancelFail: cancelFail}
+		client := newClient(t, fake)
+		client.Client.RetryMax = 0
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		_, err := client.Reindex.Reindex(ctx, &document.ReindexRequest{}, &document.ReindexOptions{PollInterval: time.Millisecond})
+		cancel()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("cancel failing %t: reindex = %v, want %v", cancelFail, err, context.DeadlineExceeded)
+		}
+		if cancelFail && (err == nil || !strings.Contains(err.Error(), "not cancellable")) {
+			t.Errorf("reindex = %v, want the failure of the cancellation attached", err)
+		}
+
+		fake.mu.Lock()
+		if fake.canceled != 1 {
+			t.Errorf("cancel failing %t: task canceled %d times, want once", cancelFail, fake.canceled)
+		}
+		fake.mu.Unlock()
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultScrollKeepAlive is the time a scroll context is kept alive between two batches
+const DefaultScrollKeepAlive = time.Minute
+
+type ScrollOptions struct {
+	// KeepAlive of the scroll context between two batches, defaults to DefaultScrollKeepAlive
+	KeepAlive time.Duration
+
+	// Size is the number of hits per batch, overrides the size of the query
+	Size int
+
+	// Slice splits the scroll into independent slices which can be consumed in parallel
+	Slice *Slice
+}
+
+type Slice struct {
+	ID  int `json:"id"`
+	Max int `json:"max"`
+}
+
+// ExportOptions of a sliced scroll. Workers defaults to the number of slices.
+type ExportOptions struct {
+	Slices    int
+	Workers   int
+	KeepAlive time.Duration
+	Size      int
+}
+
+// ScrollIterator iterates over the hits of a search in batches. It has to be closed to release the
+// scroll context.
+type ScrollIterator struct {
+	service *DocumentService
+	query   interface{}
+	options ScrollOptions
+	indices []string
+
+	scrollID string
+	total    *Total
+	done     bool
+}
+
+type scrollRequest struct {
+	Scroll   string `json:"scroll"`
+	ScrollID string `json:"scroll_id"`
+}
+
+type clearScrollRequest struct {
+	ScrollID []string `json:"scroll_id"`
+}
+
+// Scroll returns an iterator over all hits of a search, the search is sent with the first call of Next
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/paginate-search-results.html#scroll-search-results
+func (s *DocumentService) Scroll(query interface{}, options *ScrollOptions, indices ...string) *ScrollIterator {
+	it := &ScrollIterator{
+		service: s,
+		query:   query,
+		indices: indices,
+	}
+
+	if options != nil {
+		it.options = *options
+	}
+	if it.options.KeepAlive <= 0 {
+		it.options.KeepAlive = DefaultScrollKeepAlive
+	}
+
+	return it
+}
+
+// Next returns the next batch of hits, io.EOF is returned after the last batch
+func (it *ScrollIterator) Next(ctx context.Context) ([]Hit, error) {
+	ctx, done := it.service.Client.StartOperation(ctx, "documents.scroll")
+	defer done()
+
+	if it.done {
+		return nil, io.EOF
+	}
+
+	var response *SearchResponse
+	var err error
+
+	if it.scrollID == "" {
+		response, err = it.search(ctx)
+	} else {
+		request := &scrollRequest{
+			Scroll:   formatDuration(it.options.KeepAlive),
+			ScrollID: it.scrollID,
+		}
+
+		err = it.service.Client.Send(ctx, common.ScrollEndpoint, http.MethodPost, request, &response)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		it.done = true
+		return nil, io.EOF
+	}
+
+	if response.ScrollID != "" {
+		it.scrollID = response.ScrollID
+	}
+	if it.total == nil {
+		total := response.Hits.Total
+		it.total = &total
+	}
+
+	if len(response.Hits.Hits) == 0 {
+		it.done = true
+		return nil, io.EOF
+	}
+
+	return response.Hits.Hits, nil
+}
+
+// Total returns the total number of hits, it is nil until the first batch was fetched
+func (it *ScrollIterator) Total() *Total {
+	return it.total
+}
+
+// Close clears the scroll context, it is safe to call Close more than once
+func (it *ScrollIterator) Close(ctx context.Context) error {
+	ctx, done := it.service.Client.StartOperation(ctx, "documents.clear_scroll")
+	defer done()
+
+	it.done = true
+
+	if it.scrollID == "" {
+		return nil
+	}
+
+	request := &clearScrollRequest{ScrollID: []string{it.scrollID}}
+	it.scrollID = ""
+
+	_, err := it.service.Client.Do(ctx, request, common.ScrollEndpoint, http.MethodDelete)
+
+	return err
+}
+
+func (it *ScrollIterator) search(ctx context.Context) (*SearchResponse, error) {
+	endpoint := indicesPath(it.indices) + "/_search?scroll=" + formatDuration(it.options.KeepAlive)
+
+	request, err := searchBody(it.query)
+	if err != nil {
+		return nil, err
+	}
+
+	if it.options.Size > 0 {
+		request["size"] = it.options.Size
+	}
+	if it.options.Slice != nil {
+		request["slice"] = it.options.Slice
+	}
+
+	var response *SearchResponse
+
+	err = it.service.Client.Send(ctx, endpoint, http.MethodPost, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// searchBody returns the fields of a query as map, so the fields of an iterator can be added to it
+func searchBody(query interface{}) (map[string]interface{}, error) {
+	request := map[string]interface{}{}
+
+	if query == nil {
+		return request, nil
+	}
+
+	b, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+
+	err = json.Unmarshal(b, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range fields {
+		request[k] = v
+	}
+
+	return request, nil
+}
+
+// Export scrolls over all hits of a search with a sliced scroll and passes each batch to the handler.
+// The slices are consumed by a pool of workers, so the handler has to be safe for concurrent use. The
+// first error of a slice or the handler cancels the export.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/paginate-search-results.html#slice-scroll
+func (s *DocumentService) Export(ctx context.Context, query interface{}, options *ExportOptions, handler func(ctx context.Context, hits []Hit) error, indices ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "documents.export")
+	defer done()
+
+	opts := ExportOptions{Slices: 1}
+
+	if options != nil {
+		opts = *options
+	}
+	if opts.Slices <= 0 {
+		opts.Slices = 1
+	}
+	if opts.Workers <= 0 || opts.Workers > opts.Slices {
+		opts.Workers = opts.Slices
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var exportErr error
+
+	fail := func(err error) {
+		once.Do(func() {
+			exportErr = err
+			cancel()
+		})
+	}
+
+	slices := make(chan int, opts.Slices)
+	for id := 0; id < opts.Slices; id++ {
+		slices <- id
+	}
+	close(slices)
+
+	var wg sync.WaitGroup
+
+	for n := 0; n < opts.Workers; n++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for id := range slices {
+				if ctx.Err() != nil {
+					return
+				}
+
+				scrollOptions := &ScrollOptions{KeepAlive: opts.KeepAlive, Size: opts.Size}
+				if opts.Slices > 1 {
+					scrollOptions.Slice = &Slice{ID: id, Max: opts.Slices}
+				}
+
+				err := s.exportSlice(ctx, s.Scroll(query, scrollOptions, indices...), handler)
+				if err != nil {
+					fail(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if exportErr != nil {
+		return exportErr
+	}
+
+	return ctx.Err()
+}
+
+func (s *DocumentService) exportSlice(ctx context.Context, it *ScrollIterator, handler func(ctx context.Context, hits []Hit) error) (err error) {
+	defer func() {
+		// the scroll context is cleared even if the export was canceled
+		closeErr := it.Close(context.Background())
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	for {
+		hits, err := it.Next(ctx)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = handler(ctx, hits)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// formatDuration formats a duration as Elasticsearch time unit
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Nanoseconds()/int64(time.Millisecond))
+}
@@ -0,0 +1,112 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// TypedGetResult is a get result with the source decoded into T, Document is nil if the document was not
+// found or has no source
+type TypedGetResult[T any] struct {
+	*GetResult
+	Document *T
+}
+
+// TypedSearchResponse is a search response with the sources of the hits decoded into T, in the order of
+// the hits. The aggregations are not decoded.
+type TypedSearchResponse[T any] struct {
+	*SearchResponse
+	Documents []T
+}
+
+// Get a document by id with its source decoded into T, f.e.
+//
+//	result, err := document.Get[Book](ctx, client.Documents, "books", "1")
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-get.html
+func Get[T any](ctx context.Context, s DocumentServiceInterface, index string, id string) (*TypedGetResult[T], error) {
+	result, err := s.Get(ctx, index, id)
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	return decodeGetResult[T](result)
+}
+
+// MGet gets multiple documents of an index by id with their sources decoded into T, in the order of the ids
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/docs-multi-get.html
+func MGet[T any](ctx context.Context, s DocumentServiceInterface, index string, ids ...string) ([]TypedGetResult[T], error) {
+	results, err := s.MGet(ctx, index, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]TypedGetResult[T], len(results))
+
+	for i := range results {
+		result, err := decodeGetResult[T](&results[i])
+		if err != nil {
+			return nil, err
+		}
+		typed[i] = *result
+	}
+
+	return typed, nil
+}
+
+// Search indices (or all indices if none is given) with the sources of the hits decoded into T
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/search-search.html
+func Search[T any](ctx context.Context, s DocumentServiceInterface, query interface{}, indices ...string) (*TypedSearchResponse[T], error) {
+	response, err := s.Search(ctx, query, indices...)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	documents, err := DecodeHits[T](response.Hits.Hits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TypedSearchResponse[T]{SearchResponse: response, Documents: documents}, nil
+}
+
+// DecodeHits decodes the sources of hits into T, f.e. the pages of a ScrollIterator or PITIterator. Hits
+// without source are returned as zero value.
+func DecodeHits[T any](hits []Hit) ([]T, error) {
+	documents := make([]T, len(hits))
+
+	for i, hit := range hits {
+		if len(hit.Source) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(hit.Source, &documents[i]); err != nil {
+			return nil, fmt.Errorf("decode source of %s/%s: %w", hit.Index, hit.ID, err)
+		}
+	}
+
+	return documents, nil
+}
+
+func decodeGetResult[T any](result *GetResult) (*TypedGetResult[T], error) {
+	typed := &TypedGetResult[T]{GetResult: result}
+
+	if !result.Found || len(result.Source) == 0 {
+		return typed, nil
+	}
+
+	typed.Document = new(T)
+
+	if err := json.Unmarshal(result.Source, typed.Document); err != nil {
+		return nil, fmt.Errorf("decode source of %s/%s: %w", result.Index, result.ID, err)
+	}
+
+	return typed, nil
+}
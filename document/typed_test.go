@@ -0,0 +1,114 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package document_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/WhizUs/go-opendistro"
+	"github.com/WhizUs/go-opendistro/document"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type book struct {
+	Title string `json:"title"`
+	Pages int    `json:"pages"`
+}
+
+func newClient(t *testing.T, handler http.Handler) *opendistro.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := opendistro.NewClient(&opendistro.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.Logger = nil
+
+	return client
+}
+
+func TestTypedResults(t *testing.T) {
+	ctx := context.Background()
+
+	client := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/books/_doc/1":
+			fmt.Fprint(w, `{"_index":"books","_id":"1","_version":3,"found":true,"_source":{"title":"Dune","pages":412}}`)
+		case "/books/_doc/2":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"_index":"books","_id":"2","found":false}`)
+		case "/books/_mget":
+			fmt.Fprint(w, `{"docs":[{"_index":"books","_id":"1","found":true,"_source":{"title":"Dune","pages":412}},`+
+				`{"_index":"books","_id":"2","found":false}]}`)
+		case "/books/_search":
+			fmt.Fprint(w, `{"took":1,"hits":{"total":{"value":2,"relation":"eq"},"hits":[`+
+				`{"_index":"books","_id":"1","_source":{"title":"Dune","pages":412}},`+
+				`{"_index":"books","_id":"3","_source":{"title":"Emma","pages":474}}]},`+
+				`"aggregations":{"pages":{"value":886}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	result, err := document.Get[book](ctx, client.Documents, "books", "1")
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if result.Version != 3 || !reflect.DeepEqual(result.Document, &book{Title: "Dune", Pages: 412}) {
+		t.Errorf("get = %+v %+v", result.GetResult, result.Document)
+	}
+
+	missing, err := document.Get[book](ctx, client.Documents, "books", "2")
+	if err != nil {
+		t.Fatalf("get missing: %s", err)
+	}
+	if missing.Found || missing.Document != nil {
+		t.Errorf("get missing = %+v %+v", missing.GetResult, missing.Document)
+	}
+
+	results, err := document.MGet[book](ctx, client.Documents, "books", "1", "2")
+	if err != nil {
+		t.Fatalf("mget: %s", err)
+	}
+	if len(results) != 2 || results[0].Document.Title != "Dune" || results[1].Document != nil {
+		t.Errorf("mget = %+v", results)
+	}
+
+	response, err := document.Search[book](ctx, client.Documents, map[string]interface{}{"query": map[string]interface{}{"match_all": struct{}{}}}, "books")
+	if err != nil {
+		t.Fatalf("search: %s", err)
+	}
+
+	want := []book{{Title: "Dune", Pages: 412}, {Title: "Emma", Pages: 474}}
+	if !reflect.DeepEqual(response.Documents, want) {
+		t.Errorf("documents = %+v, want %+v", response.Documents, want)
+	}
+	if response.Hits.Total.Value != 2 || string(response.Aggregations["pages"]) != `{"value":886}` {
+		t.Errorf("search response = %+v", response.SearchResponse)
+	}
+}
+
+func TestDecodeHits(t *testing.T) {
+	documents, err := document.DecodeHits[book]([]document.Hit{
+		{ID: "1", Source: []byte(`{"title":"Dune"}`)},
+		{ID: "2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(documents, []book{{Title: "Dune"}, {}}) {
+		t.Errorf("documents = %+v", documents)
+	}
+
+	_, err = document.DecodeHits[book]([]document.Hit{{Index: "books", ID: "1", Source: []byte(`{"pages":"many"}`)}})
+	if err == nil {
+		t.Error("decoding an invalid source returned no error")
+	}
+}
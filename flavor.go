@@ -0,0 +1,92 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Flavor selects the paths of the plugin APIs, which moved from /_opendistro/ to /_plugins/ in OpenSearch
+type Flavor string
+
+const (
+	// FlavorOpenDistro uses the /_opendistro/ paths, it is the default
+	FlavorOpenDistro Flavor = "opendistro"
+
+	// FlavorOpenSearch uses the /_plugins/ paths
+	FlavorOpenSearch Flavor = "opensearch"
+
+	// FlavorAuto detects the flavor by the distribution of the root endpoint with the first request of a
+	// plugin API
+	FlavorAuto Flavor = "auto"
+
+	openDistroPrefix = "/_opendistro/"
+	pluginsPrefix    = "/_plugins/"
+)
+
+// flavorState is shared by the client and its copies, so the flavor is detected only once
+type flavorState struct {
+	mu     sync.Mutex
+	client *Client
+	flavor Flavor
+}
+
+// Flavor returns the flavor of the client, FlavorAuto is returned until the flavor was detected
+func (c *Client) Flavor() Flavor {
+	c.flavor.mu.Lock()
+	defer c.flavor.mu.Unlock()
+
+	return c.flavor.flavor
+}
+
+// pluginPath rewrites the path of a plugin API to the flavor of the cluster
+func (c *Client) pluginPath(ctx context.Context, endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, openDistroPrefix) {
+		return endpoint, nil
+	}
+
+	flavor, err := c.flavor.resolve(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if flavor == FlavorOpenSearch {
+		return pluginsPrefix + strings.TrimPrefix(endpoint, openDistroPrefix), nil
+	}
+
+	return endpoint, nil
+}
+
+// resolve returns the flavor, FlavorAuto is detected without holding the lock: concurrent callers share
+// the request of the root endpoint and a caller whose context is done does not block the others
+func (s *flavorState) resolve(ctx context.Context) (Flavor, error) {
+	s.mu.Lock()
+	flavor := s.flavor
+	s.mu.Unlock()
+
+	if flavor != FlavorAuto {
+		return flavor, nil
+	}
+
+	distribution, err := s.client.info.distribution(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Open Distro does not report a distribution
+	flavor = FlavorOpenDistro
+	if distribution == "opensearch" {
+		flavor = FlavorOpenSearch
+	}
+
+	s.mu.Lock()
+	s.flavor = flavor
+	s.mu.Unlock()
+
+	return flavor, nil
+}
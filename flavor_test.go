@@ -0,0 +1,64 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFlavorAuto(t *testing.T) {
+	var roots int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			atomic.AddInt32(&roots, 1)
+			fmt.Fprint(w, `{"name":"node-1","version":{"distribution":"opensearch","number":"1.3.0"}}`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{BaseURL: server.URL, Flavor: FlavorAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Client.Logger = nil
+
+	if flavor := client.Flavor(); flavor != FlavorAuto {
+		t.Errorf("flavor before the first request = %s", flavor)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			path, err := client.pluginPath(context.Background(), "/_opendistro/_security/api/account")
+			if err != nil || path != "/_plugins/_security/api/account" {
+				t.Errorf("path = %s, %v", path, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&roots); n != 1 {
+		t.Errorf("root requests = %d, want 1", n)
+	}
+	if flavor := client.Flavor(); flavor != FlavorOpenSearch {
+		t.Errorf("flavor = %s, want %s", flavor, FlavorOpenSearch)
+	}
+}
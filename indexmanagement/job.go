@@ -0,0 +1,57 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexmanagement
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Schedule defines when a job runs, either in an interval or by a cron expression
+type Schedule struct {
+	Interval *ScheduleInterval `json:"interval,omitempty"`
+	Cron     *CronSchedule     `json:"cron,omitempty"`
+}
+
+// ScheduleInterval runs a job every period units (f.e. "Minutes", "Hours", "Days"). The start time is in
+// epoch milliseconds.
+type ScheduleInterval struct {
+	Period    int    `json:"period"`
+	Unit      string `json:"unit"`
+	StartTime int64  `json:"start_time,omitempty"`
+}
+
+type CronSchedule struct {
+	Expression string `json:"expression"`
+	Timezone   string `json:"timezone"`
+}
+
+// JobResponse is returned when a job is created, fetched or updated. The sequence number and primary
+// term are required to update the job.
+type JobResponse struct {
+	ID          string `json:"_id"`
+	Version     int64  `json:"_version"`
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+}
+
+// ExplainStats are the statistics of the pages processed by a job
+type ExplainStats struct {
+	PagesProcessed     int64 `json:"pages_processed"`
+	DocumentsProcessed int64 `json:"documents_processed"`
+	DocumentsIndexed   int64 `json:"documents_indexed,omitempty"`
+	RollupsIndexed     int64 `json:"rollups_indexed,omitempty"`
+	IndexTimeInMillis  int64 `json:"index_time_in_millis"`
+	SearchTimeInMillis int64 `json:"search_time_in_millis"`
+}
+
+func concurrencyParams(seqNo int64, primaryTerm int64) string {
+	params := url.Values{}
+	params.Set("if_seq_no", fmt.Sprintf("%d", seqNo))
+	params.Set("if_primary_term", fmt.Sprintf("%d", primaryTerm))
+
+	return params.Encode()
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexmanagement
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type RollupService common.Service
+
+type RollupServiceInterface interface {
+	Get(ctx context.Context, id string) (*RollupResponse, error)
+	Create(ctx context.Context, id string, rollup *Rollup) (*RollupResponse, error)
+	Update(ctx context.Context, id string, seqNo int64, primaryTerm int64, rollup *Rollup) (*RollupResponse, error)
+	Delete(ctx context.Context, id string) error
+	Start(ctx context.Context, id string) error
+	Stop(ctx context.Context, id string) error
+	Explain(ctx context.Context, id string) (*RollupExplain, error)
+}
+
+type Rollup struct {
+	SourceIndex       string        `json:"source_index"`
+	TargetIndex       string        `json:"target_index"`
+	Description       string        `json:"description,omitempty"`
+	Schedule          Schedule      `json:"schedule"`
+	Enabled           bool          `json:"enabled"`
+	Continuous        bool          `json:"continuous"`
+	PageSize          int           `json:"page_size"`
+	Delay             int64         `json:"delay,omitempty"`
+	Dimensions        []Dimension   `json:"dimensions"`
+	Metrics           []MetricField `json:"metrics,omitempty"`
+	Roles             []string      `json:"roles,omitempty"`
+	ErrorNotification interface{}   `json:"error_notification,omitempty"`
+	EnabledTime       int64         `json:"enabled_time,omitempty"`
+	LastUpdatedTime   int64         `json:"last_updated_time,omitempty"`
+	SchemaVersion     int           `json:"schema_version,omitempty"`
+}
+
+// Dimension groups the source documents, exactly one of the fields has to be set. The first dimension
+// of a rollup has to be a date histogram.
+type Dimension struct {
+	DateHistogram *DateHistogram `json:"date_histogram,omitempty"`
+	Terms         *Terms         `json:"terms,omitempty"`
+	Histogram     *Histogram     `json:"histogram,omitempty"`
+}
+
+type DateHistogram struct {
+	SourceField      string `json:"source_field"`
+	TargetField      string `json:"target_field,omitempty"`
+	FixedInterval    string `json:"fixed_interval,omitempty"`
+	CalendarInterval string `json:"calendar_interval,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
+}
+
+type Terms struct {
+	SourceField string `json:"source_field"`
+	TargetField string `json:"target_field,omitempty"`
+}
+
+type Histogram struct {
+	SourceField string  `json:"source_field"`
+	TargetField string  `json:"target_field,omitempty"`
+	Interval    float64 `json:"interval"`
+}
+
+// MetricField lists the metrics calculated for a source field, see NewMetricField
+type MetricField struct {
+	SourceField string   `json:"source_field"`
+	Metrics     []Metric `json:"metrics"`
+}
+
+// Metric is a metric by name with its (empty) options, f.e. {"avg":{}}
+type Metric map[string]struct{}
+
+type RollupResponse struct {
+	JobResponse
+	Rollup *Rollup `json:"rollup"`
+}
+
+type rollupRequest struct {
+	Rollup *Rollup `json:"rollup"`
+}
+
+// RollupExplain is the metadata of a rollup job
+type RollupExplain struct {
+	MetadataID string          `json:"metadata_id"`
+	Metadata   *RollupMetadata `json:"rollup_metadata"`
+}
+
+type RollupMetadata struct {
+	RollupID        string            `json:"rollup_id"`
+	LastUpdatedTime int64             `json:"last_updated_time"`
+	Continuous      *ContinuousWindow `json:"continuous,omitempty"`
+	Status          string            `json:"status"`
+	FailureReason   string            `json:"failure_reason,omitempty"`
+	Stats           ExplainStats      `json:"stats"`
+}
+
+type ContinuousWindow struct {
+	NextWindowStartTime int64 `json:"next_window_start_time"`
+	NextWindowEndTime   int64 `json:"next_window_end_time"`
+}
+
+// NewMetricField returns the metrics (avg, sum, max, min or value_count) to calculate for a field
+func NewMetricField(sourceField string, metrics ...string) MetricField {
+	field := MetricField{SourceField: sourceField}
+
+	for _, metric := range metrics {
+		field.Metrics = append(field.Metrics, Metric{metric: struct{}{}})
+	}
+
+	return field
+}
+
+// Get a single rollup job by id
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#get-an-index-rollup-job
+func (s *RollupService) Get(ctx context.Context, id string) (*RollupResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.get")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id
+
+	var rollup *RollupResponse
+
+	err := s.Client.Get(ctx, endpoint, &rollup)
+	if err != nil {
+		return nil, err
+	}
+
+	return rollup, nil
+}
+
+// Create a rollup job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#create-or-update-an-index-rollup-job
+func (s *RollupService) Create(ctx context.Context, id string, rollup *Rollup) (*RollupResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.create")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id
+
+	var response *RollupResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, &rollupRequest{Rollup: rollup}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Update a rollup job. The sequence number and primary term of the current version are required.
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#create-or-update-an-index-rollup-job
+func (s *RollupService) Update(ctx context.Context, id string, seqNo int64, primaryTerm int64, rollup *Rollup) (*RollupResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.update")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id + "?" + concurrencyParams(seqNo, primaryTerm)
+
+	var response *RollupResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, &rollupRequest{Rollup: rollup}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Delete a rollup job by id
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#delete-an-index-rollup-job
+func (s *RollupService) Delete(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.delete")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
+
+// Start a rollup job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#start-or-stop-an-index-rollup-job
+func (s *RollupService) Start(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.start")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id + "/_start"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Stop a rollup job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#start-or-stop-an-index-rollup-job
+func (s *RollupService) Stop(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.stop")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id + "/_stop"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Explain returns the status and statistics of a rollup job, a *common.NotFoundError is returned if it
+// does not exist
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-rollups/rollup-api/#explain-an-index-rollup-job
+func (s *RollupService) Explain(ctx context.Context, id string) (*RollupExplain, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.rollups.explain")
+	defer done()
+
+	endpoint := common.RollupsEndpoint + id + "/_explain"
+
+	var explains map[string]*RollupExplain
+
+	err := s.Client.Get(ctx, endpoint, &explains)
+	if err != nil {
+		return nil, err
+	}
+
+	explain := explains[id]
+	if explain == nil {
+		return nil, &common.NotFoundError{Resource: "rollup", Name: id}
+	}
+
+	return explain, nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexmanagement
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type TransformService common.Service
+
+type TransformServiceInterface interface {
+	Get(ctx context.Context, id string) (*TransformResponse, error)
+	Create(ctx context.Context, id string, transform *Transform) (*TransformResponse, error)
+	Update(ctx context.Context, id string, seqNo int64, primaryTerm int64, transform *Transform) (*TransformResponse, error)
+	Delete(ctx context.Context, id string) error
+	Start(ctx context.Context, id string) error
+	Stop(ctx context.Context, id string) error
+	Explain(ctx context.Context, id string) (*TransformExplain, error)
+	Preview(ctx context.Context, transform *Transform) ([]json.RawMessage, error)
+}
+
+// Transform summarizes the documents of the source index into the target index. The documents are grouped
+// by the groups (same structure as rollup dimensions) and summarized by Elasticsearch aggregations,
+// f.e. {"quantity":{"sum":{"field":"total_quantity"}}}.
+type Transform struct {
+	SourceIndex        string                 `json:"source_index"`
+	TargetIndex        string                 `json:"target_index"`
+	Description        string                 `json:"description,omitempty"`
+	Schedule           Schedule               `json:"schedule"`
+	Enabled            bool                   `json:"enabled"`
+	Continuous         bool                   `json:"continuous,omitempty"`
+	PageSize           int                    `json:"page_size"`
+	DataSelectionQuery interface{}            `json:"data_selection_query,omitempty"`
+	Groups             []Dimension            `json:"groups"`
+	Aggregations       map[string]interface{} `json:"aggregations,omitempty"`
+	Roles              []string               `json:"roles,omitempty"`
+	EnabledAt          int64                  `json:"enabled_at,omitempty"`
+	UpdatedAt          int64                  `json:"updated_at,omitempty"`
+	SchemaVersion      int                    `json:"schema_version,omitempty"`
+}
+
+type TransformResponse struct {
+	JobResponse
+	Transform *Transform `json:"transform"`
+}
+
+type transformRequest struct {
+	Transform *Transform `json:"transform"`
+}
+
+// TransformExplain is the metadata of a transform job
+type TransformExplain struct {
+	MetadataID string             `json:"metadata_id"`
+	Metadata   *TransformMetadata `json:"transform_metadata"`
+}
+
+type TransformMetadata struct {
+	TransformID   string       `json:"transform_id"`
+	LastUpdatedAt int64        `json:"last_updated_at"`
+	Status        string       `json:"status"`
+	FailureReason string       `json:"failure_reason,omitempty"`
+	Stats         ExplainStats `json:"stats"`
+}
+
+type transformPreview struct {
+	Documents []json.RawMessage `json:"documents"`
+}
+
+// Get a single transform job by id
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#get-a-transform-jobs-details
+func (s *TransformService) Get(ctx context.Context, id string) (*TransformResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.get")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id
+
+	var transform *TransformResponse
+
+	err := s.Client.Get(ctx, endpoint, &transform)
+	if err != nil {
+		return nil, err
+	}
+
+	return transform, nil
+}
+
+// Create a transform job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#create-a-transform-job
+func (s *TransformService) Create(ctx context.Context, id string, transform *Transform) (*TransformResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.create")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id
+
+	var response *TransformResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, &transformRequest{Transform: transform}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Update a transform job. The sequence number and primary term of the current version are required.
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#update-a-transform-job
+func (s *TransformService) Update(ctx context.Context, id string, seqNo int64, primaryTerm int64, transform *Transform) (*TransformResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.update")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id + "?" + concurrencyParams(seqNo, primaryTerm)
+
+	var response *TransformResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, &transformRequest{Transform: transform}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Delete a transform job by id
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#delete-a-transform-job
+func (s *TransformService) Delete(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.delete")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
+
+// Start a transform job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#start-a-transform-job
+func (s *TransformService) Start(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.start")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id + "/_start"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Stop a transform job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#stop-a-transform-job
+func (s *TransformService) Stop(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.stop")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id + "/_stop"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Explain returns the status and statistics of a transform job, a *common.NotFoundError is returned if it
+// does not exist
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#get-the-status-of-a-transform-job
+func (s *TransformService) Explain(ctx context.Context, id string) (*TransformExplain, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.explain")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + id + "/_explain"
+
+	var explains map[string]*TransformExplain
+
+	err := s.Client.Get(ctx, endpoint, &explains)
+	if err != nil {
+		return nil, err
+	}
+
+	explain := explains[id]
+	if explain == nil {
+		return nil, &common.NotFoundError{Resource: "transform", Name: id}
+	}
+
+	return explain, nil
+}
+
+// Preview returns the documents a transform job would create, without creating the job
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/im/index-transforms/transforms-apis/#preview-a-transform-jobs-results
+func (s *TransformService) Preview(ctx context.Context, transform *Transform) ([]json.RawMessage, error) {
+	ctx, done := s.Client.StartOperation(ctx, "index_management.transforms.preview")
+	defer done()
+
+	endpoint := common.TransformsEndpoint + "_preview"
+
+	var preview *transformPreview
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, &transformRequest{Transform: transform}, &preview)
+	if err != nil || preview == nil {
+		return nil, err
+	}
+
+	return preview.Documents, nil
+}
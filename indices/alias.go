@@ -0,0 +1,118 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indices
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type AliasService common.Service
+
+type AliasServiceInterface interface {
+	Get(ctx context.Context, names ...string) (map[string]map[string]*AliasProps, error)
+	Exists(ctx context.Context, alias string) (bool, error)
+	Update(ctx context.Context, actions ...AliasAction) error
+}
+
+// AliasProps are the properties of an alias
+type AliasProps struct {
+	Filter        interface{} `json:"filter,omitempty"`
+	Routing       string      `json:"routing,omitempty"`
+	IndexRouting  string      `json:"index_routing,omitempty"`
+	SearchRouting string      `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool       `json:"is_write_index,omitempty"`
+	IsHidden      *bool       `json:"is_hidden,omitempty"`
+}
+
+// AliasAction is one action of an atomic alias update, exactly one of the fields has to be set
+type AliasAction struct {
+	Add         *Alias `json:"add,omitempty"`
+	Remove      *Alias `json:"remove,omitempty"`
+	RemoveIndex *Alias `json:"remove_index,omitempty"`
+}
+
+type Alias struct {
+	Index   string   `json:"index,omitempty"`
+	Indices []string `json:"indices,omitempty"`
+	Alias   string   `json:"alias,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	AliasProps
+}
+
+type aliasesRequest struct {
+	Actions []AliasAction `json:"actions"`
+}
+
+type indexAliases struct {
+	Aliases map[string]*AliasProps `json:"aliases"`
+}
+
+// AddAlias returns an action adding an alias to an index
+func AddAlias(index string, alias string) AliasAction {
+	return AliasAction{Add: &Alias{Index: index, Alias: alias}}
+}
+
+// RemoveAlias returns an action removing an alias from an index
+func RemoveAlias(index string, alias string) AliasAction {
+	return AliasAction{Remove: &Alias{Index: index, Alias: alias}}
+}
+
+// Get the aliases of indices (or all indices if none is given), by index and alias name
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-get-alias.html
+func (s *AliasService) Get(ctx context.Context, names ...string) (map[string]map[string]*AliasProps, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.aliases.get")
+	defer done()
+
+	path, err := optionalIndexPath(names)
+	if err != nil {
+		return nil, err
+	}
+
+	var indicesAliases map[string]*indexAliases
+
+	err = s.Client.Get(ctx, path+"/_alias", &indicesAliases)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]map[string]*AliasProps, len(indicesAliases))
+	for name, ia := range indicesAliases {
+		aliases[name] = ia.Aliases
+	}
+
+	return aliases, nil
+}
+
+// Exists reports whether an alias exists
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-alias-exists.html
+func (s *AliasService) Exists(ctx context.Context, alias string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.aliases.exists")
+	defer done()
+
+	// HEAD /_alias/ would report whether any alias exists
+	if alias == "" {
+		return false, errors.New("alias name is required")
+	}
+
+	return s.Client.Exists(ctx, "/_alias/"+alias)
+}
+
+// Update applies the alias actions atomically, f.e. to switch an alias from one index to another
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-aliases.html
+func (s *AliasService) Update(ctx context.Context, actions ...AliasAction) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.aliases.update")
+	defer done()
+
+	_, err := s.Client.Do(ctx, &aliasesRequest{Actions: actions}, common.AliasesEndpoint, http.MethodPost)
+
+	return err
+}
@@ -0,0 +1,273 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indices
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var (
+	errIndexRequired     = errors.New("at least one index is required")
+	errIndexNameRequired = errors.New("index name is required")
+)
+
+type IndexService common.Service
+
+type IndexServiceInterface interface {
+	Create(ctx context.Context, name string, index *Index) error
+	Delete(ctx context.Context, names ...string) error
+	Exists(ctx context.Context, name string) (bool, error)
+	Open(ctx context.Context, names ...string) error
+	Close(ctx context.Context, names ...string) error
+	Refresh(ctx context.Context, names ...string) error
+	Flush(ctx context.Context, names ...string) error
+	ForceMerge(ctx context.Context, maxNumSegments int, names ...string) error
+	Cat(ctx context.Context, pattern string) ([]CatIndex, error)
+}
+
+// Index is the definition of an index to create. Settings can be given nested or by their flat key
+// (f.e. "index.number_of_shards").
+type Index struct {
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings *Mapping               `json:"mappings,omitempty"`
+	Aliases  map[string]*AliasProps `json:"aliases,omitempty"`
+}
+
+// CatIndex is a row of the cat indices API. Counts and sizes (in bytes) are 0 for closed indices.
+type CatIndex struct {
+	Health           string
+	Status           string
+	Index            string
+	UUID             string
+	Primaries        int
+	Replicas         int
+	DocsCount        int64
+	DocsDeleted      int64
+	StoreSize        int64
+	PrimaryStoreSize int64
+}
+
+type catIndex struct {
+	Health           string  `json:"health"`
+	Status           string  `json:"status"`
+	Index            string  `json:"index"`
+	UUID             string  `json:"uuid"`
+	Primaries        *string `json:"pri"`
+	Replicas         *string `json:"rep"`
+	DocsCount        *string `json:"docs.count"`
+	DocsDeleted      *string `json:"docs.deleted"`
+	StoreSize        *string `json:"store.size"`
+	PrimaryStoreSize *string `json:"pri.store.size"`
+}
+
+// Create an index with settings, mappings and aliases
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-create-index.html
+func (s *IndexService) Create(ctx context.Context, name string, index *Index) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.create")
+	defer done()
+
+	if name == "" {
+		return errIndexNameRequired
+	}
+
+	_, err := s.Client.Do(ctx, index, "/"+name, http.MethodPut)
+
+	return err
+}
+
+// Delete one or more indices
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-delete-index.html
+func (s *IndexService) Delete(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.delete")
+	defer done()
+
+	path, err := indexPath(names)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Do(ctx, nil, path, http.MethodDelete)
+
+	return err
+}
+
+// Exists reports whether an index (or alias) exists
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-exists.html
+func (s *IndexService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.exists")
+	defer done()
+
+	// HEAD / would report the cluster as existing index
+	if name == "" {
+		return false, errIndexNameRequired
+	}
+
+	return s.Client.Exists(ctx, "/"+name)
+}
+
+// Open closed indices
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-open-close.html
+func (s *IndexService) Open(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.open")
+	defer done()
+
+	if len(names) == 0 {
+		return errIndexRequired
+	}
+
+	return s.post(ctx, names, "/_open")
+}
+
+// Close indices, closed indices block reads and writes
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-close.html
+func (s *IndexService) Close(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.close")
+	defer done()
+
+	if len(names) == 0 {
+		return errIndexRequired
+	}
+
+	return s.post(ctx, names, "/_close")
+}
+
+// Refresh makes the recent changes of indices (or all indices if none is given) visible to search
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-refresh.html
+func (s *IndexService) Refresh(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.refresh")
+	defer done()
+
+	return s.post(ctx, names, "/_refresh")
+}
+
+// Flush writes the transaction log of indices (or all indices if none is given) to the index store
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-flush.html
+func (s *IndexService) Flush(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.flush")
+	defer done()
+
+	return s.post(ctx, names, "/_flush")
+}
+
+// ForceMerge merges the segments of indices (or all indices if none is given). With maxNumSegments 0 the
+// index decides whether merging is required.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-forcemerge.html
+func (s *IndexService) ForceMerge(ctx context.Context, maxNumSegments int, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.force_merge")
+	defer done()
+
+	action := "/_forcemerge"
+
+	if maxNumSegments > 0 {
+		action = action + "?max_num_segments=" + strconv.Itoa(maxNumSegments)
+	}
+
+	return s.post(ctx, names, action)
+}
+
+// Cat lists the indices matching the pattern (all indices if empty) with their health and sizes
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cat-indices.html
+func (s *IndexService) Cat(ctx context.Context, pattern string) ([]CatIndex, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.index.cat")
+	defer done()
+
+	endpoint := common.CatIndicesEndpoint
+
+	if pattern != "" {
+		endpoint = endpoint + "/" + pattern
+	}
+
+	endpoint = endpoint + "?format=json&bytes=b"
+
+	var rows []catIndex
+
+	err := s.Client.Get(ctx, endpoint, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]CatIndex, 0, len(rows))
+
+	for _, row := range rows {
+		indices = append(indices, CatIndex{
+			Health:           row.Health,
+			Status:           row.Status,
+			Index:            row.Index,
+			UUID:             row.UUID,
+			Primaries:        int(parseCount(row.Primaries)),
+			Replicas:         int(parseCount(row.Replicas)),
+			DocsCount:        parseCount(row.DocsCount),
+			DocsDeleted:      parseCount(row.DocsDeleted),
+			StoreSize:        parseCount(row.StoreSize),
+			PrimaryStoreSize: parseCount(row.PrimaryStoreSize),
+		})
+	}
+
+	return indices, nil
+}
+
+func (s *IndexService) post(ctx context.Context, names []string, action string) error {
+	path, err := optionalIndexPath(names)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Do(ctx, nil, path+action, http.MethodPost)
+
+	return err
+}
+
+// indexPath returns the path of one or more indices. Without names or with an empty name the request
+// would be sent to the root endpoint or apply to all indices.
+func indexPath(names []string) (string, error) {
+	if len(names) == 0 {
+		return "", errIndexRequired
+	}
+
+	for _, name := range names {
+		if name == "" {
+			return "", errIndexNameRequired
+		}
+	}
+
+	return "/" + strings.Join(names, ","), nil
+}
+
+// optionalIndexPath returns the path of the indices, or an empty path for all indices if none is given
+func optionalIndexPath(names []string) (string, error) {
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	return indexPath(names)
+}
+
+func parseCount(value *string) int64 {
+	if value == nil {
+		return 0
+	}
+
+	count, err := strconv.ParseInt(*value, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return count
+}
@@ -0,0 +1,77 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indices
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type MappingService common.Service
+
+type MappingServiceInterface interface {
+	Get(ctx context.Context, names ...string) (map[string]*Mapping, error)
+	Put(ctx context.Context, mapping *Mapping, names ...string) error
+}
+
+// Mapping of the fields of an index, f.e. {"properties":{"title":{"type":"text"}}}
+type Mapping struct {
+	Dynamic          interface{}              `json:"dynamic,omitempty"`
+	DynamicTemplates []map[string]interface{} `json:"dynamic_templates,omitempty"`
+	Source           map[string]interface{}   `json:"_source,omitempty"`
+	Meta             map[string]interface{}   `json:"_meta,omitempty"`
+	Properties       map[string]interface{}   `json:"properties,omitempty"`
+}
+
+type indexMapping struct {
+	Mappings *Mapping `json:"mappings"`
+}
+
+// Get the mappings of indices (or all indices if none is given) by index name
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-get-mapping.html
+func (s *MappingService) Get(ctx context.Context, names ...string) (map[string]*Mapping, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.mappings.get")
+	defer done()
+
+	path, err := optionalIndexPath(names)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexMappings map[string]*indexMapping
+
+	err = s.Client.Get(ctx, path+"/_mapping", &indexMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make(map[string]*Mapping, len(indexMappings))
+	for name, m := range indexMappings {
+		mappings[name] = m.Mappings
+	}
+
+	return mappings, nil
+}
+
+// Put adds fields to the mappings of indices. Existing fields can not be changed, apart from a few
+// mapping parameters.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-put-mapping.html
+func (s *MappingService) Put(ctx context.Context, mapping *Mapping, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.mappings.put")
+	defer done()
+
+	path, err := indexPath(names)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Do(ctx, mapping, path+"/_mapping", http.MethodPut)
+
+	return err
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indices
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type SettingsService common.Service
+
+type SettingsServiceInterface interface {
+	Get(ctx context.Context, names ...string) (map[string]map[string]interface{}, error)
+	Put(ctx context.Context, settings map[string]interface{}, names ...string) error
+}
+
+type indexSettings struct {
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// Get the settings of indices (or all indices if none is given) by index name. The settings are returned
+// by their flat key, f.e. "index.number_of_replicas".
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-get-settings.html
+func (s *SettingsService) Get(ctx context.Context, names ...string) (map[string]map[string]interface{}, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.settings.get")
+	defer done()
+
+	path, err := optionalIndexPath(names)
+	if err != nil {
+		return nil, err
+	}
+
+	var indicesSettings map[string]*indexSettings
+
+	err = s.Client.Get(ctx, path+"/_settings?flat_settings=true", &indicesSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]map[string]interface{}, len(indicesSettings))
+	for name, is := range indicesSettings {
+		settings[name] = is.Settings
+	}
+
+	return settings, nil
+}
+
+// Put updates dynamic settings of indices (or all indices if none is given). A setting is reset to its
+// default by putting a nil value.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-update-settings.html
+func (s *SettingsService) Put(ctx context.Context, settings map[string]interface{}, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.settings.put")
+	defer done()
+
+	path, err := optionalIndexPath(names)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Client.Do(ctx, settings, path+"/_settings", http.MethodPut)
+
+	return err
+}
@@ -0,0 +1,130 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indices
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type TemplateService common.Service
+
+// errTemplateNameRequired is returned for an empty name, the request would address all templates otherwise
+var errTemplateNameRequired = errors.New("template name is required")
+
+type TemplateServiceInterface interface {
+	Get(ctx context.Context, name string) (*Template, error)
+	List(ctx context.Context) (map[string]*Template, error)
+	Put(ctx context.Context, name string, template *Template) error
+	Delete(ctx context.Context, name string) error
+	Exists(ctx context.Context, name string) (bool, error)
+}
+
+// Template is a legacy index template applied to new indices matching the index patterns
+type Template struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Order         int                    `json:"order,omitempty"`
+	Version       int                    `json:"version,omitempty"`
+	Settings      map[string]interface{} `json:"settings,omitempty"`
+	Mappings      *Mapping               `json:"mappings,omitempty"`
+	Aliases       map[string]*AliasProps `json:"aliases,omitempty"`
+}
+
+// Get a single template by name, a *common.NotFoundError is returned if it does not exist
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-get-template-v1.html
+func (s *TemplateService) Get(ctx context.Context, name string) (*Template, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.templates.get")
+	defer done()
+
+	if name == "" {
+		return nil, errTemplateNameRequired
+	}
+
+	endpoint := common.TemplatesEndpoint + name
+
+	var templates map[string]*Template
+
+	err := s.Client.Get(ctx, endpoint, &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	if templates[name] == nil {
+		return nil, &common.NotFoundError{Resource: "template", Name: name}
+	}
+
+	return templates[name], nil
+}
+
+// List all templates by name
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-get-template-v1.html
+func (s *TemplateService) List(ctx context.Context) (map[string]*Template, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.templates.list")
+	defer done()
+
+	var templates map[string]*Template
+
+	err := s.Client.Get(ctx, common.TemplatesEndpoint, &templates)
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// Put creates or replaces a template
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-templates-v1.html
+func (s *TemplateService) Put(ctx context.Context, name string, template *Template) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.templates.put")
+	defer done()
+
+	if name == "" {
+		return errTemplateNameRequired
+	}
+
+	endpoint := common.TemplatesEndpoint + name
+
+	_, err := s.Client.Do(ctx, template, endpoint, http.MethodPut)
+
+	return err
+}
+
+// Delete a template by name
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-delete-template-v1.html
+func (s *TemplateService) Delete(ctx context.Context, name string) error {
+	ctx, done := s.Client.StartOperation(ctx, "indices.templates.delete")
+	defer done()
+
+	if name == "" {
+		return errTemplateNameRequired
+	}
+
+	endpoint := common.TemplatesEndpoint + name
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
+
+// Exists reports whether a template exists
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/indices-template-exists-v1.html
+func (s *TemplateService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "indices.templates.exists")
+	defer done()
+
+	if name == "" {
+		return false, errTemplateNameRequired
+	}
+
+	return s.Client.Exists(ctx, common.TemplatesEndpoint+name)
+}
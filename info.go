@@ -0,0 +1,344 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Plugins by the name without "opendistro" or "opensearch" prefix, see ServerInfo.HasPlugin
+const (
+	PluginSecurity            = "security"
+	PluginSQL                 = "sql"
+	PluginAnomalyDetection    = "anomaly-detection"
+	PluginKNN                 = "knn"
+	PluginPerformanceAnalyzer = "performance-analyzer"
+	PluginIndexManagement     = "index-management"
+	PluginAlerting            = "alerting"
+)
+
+// unsupportedCheckBackoff is the time a failed fetch of the server info is not retried by the check for
+// unsupported endpoints, f.e. if the user lacks the permission of the cat plugins API
+const unsupportedCheckBackoff = time.Minute
+
+// pluginAPIs are the plugins serving the APIs by their path below /_opendistro/
+var pluginAPIs = map[string]string{
+	"_security":            PluginSecurity,
+	"_sql":                 PluginSQL,
+	"_ppl":                 PluginSQL,
+	"_anomaly_detection":   PluginAnomalyDetection,
+	"_knn":                 PluginKNN,
+	"_performanceanalyzer": PluginPerformanceAnalyzer,
+	"_rollup":              PluginIndexManagement,
+	"_transform":           PluginIndexManagement,
+	"_ism":                 PluginIndexManagement,
+	"_alerting":            PluginAlerting,
+}
+
+// ServerInfo describes the connected cluster
+type ServerInfo struct {
+	Name          string
+	ClusterName   string
+	ClusterUUID   string
+	Version       string
+	LuceneVersion string
+
+	// Distribution is "elasticsearch" for Open Distro and "opensearch" for OpenSearch
+	Distribution string
+
+	// Plugins are the versions of the installed plugins by their component name, f.e.
+	// "opendistro_security"
+	Plugins map[string]string
+}
+
+type rootResponse struct {
+	Name        string `json:"name"`
+	ClusterName string `json:"cluster_name"`
+	ClusterUUID string `json:"cluster_uuid"`
+	Version     struct {
+		Distribution  string `json:"distribution"`
+		Number        string `json:"number"`
+		LuceneVersion string `json:"lucene_version"`
+	} `json:"version"`
+}
+
+type catPlugin struct {
+	Name      string `json:"name"`
+	Component string `json:"component"`
+	Version   string `json:"version"`
+}
+
+// serverInfoState caches the server info, it is shared by the client and its copies
+type serverInfoState struct {
+	client *Client
+	root   flight
+	info   flight
+
+	mu sync.Mutex
+
+	// checkFailed is the time the fetch of the server info for the check of unsupported endpoints failed
+	checkFailed time.Time
+}
+
+// flight fetches a value, concurrent callers wait for the running fetch and share its result instead of
+// sending the same requests. Only a fetched value is cached, so a failed fetch is retried by the next
+// caller.
+type flight struct {
+	mu    sync.Mutex
+	value interface{}
+	call  *flightCall
+}
+
+type flightCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// HasPlugin reports whether a plugin is installed, f.e. HasPlugin(PluginSecurity) for opendistro_security
+// or opensearch-security
+func (i *ServerInfo) HasPlugin(name string) bool {
+	_, ok := i.PluginVersion(name)
+
+	return ok
+}
+
+// PluginVersion returns the version of a plugin, see HasPlugin
+func (i *ServerInfo) PluginVersion(name string) (string, bool) {
+	for component, version := range i.Plugins {
+		if pluginName(component) == name {
+			return version, true
+		}
+	}
+
+	return "", false
+}
+
+// ServerInfo returns the version and the installed plugins of the cluster. It is fetched from the root
+// endpoint and the cat plugins API once and cached afterwards.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cat-plugins.html
+func (c *Client) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	return c.info.serverInfo(ctx)
+}
+
+// Distribution returns the distribution of the cluster, "opensearch" for OpenSearch and "elasticsearch" for
+// Open Distro. It is fetched from the root endpoint once and cached afterwards.
+func (c *Client) Distribution(ctx context.Context) (string, error) {
+	distribution, err := c.info.distribution(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if distribution == "" {
+		return "elasticsearch", nil
+	}
+
+	return distribution, nil
+}
+
+func (s *serverInfoState) serverInfo(ctx context.Context) (*ServerInfo, error) {
+	info, err := s.info.get(ctx, func(ctx context.Context) (interface{}, error) {
+		root, err := s.rootInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var plugins []catPlugin
+
+		err = s.client.Get(ctx, common.CatPluginsEndpoint+"?format=json", &plugins)
+		if err != nil {
+			return nil, err
+		}
+
+		info := &ServerInfo{
+			Name:          root.Name,
+			ClusterName:   root.ClusterName,
+			ClusterUUID:   root.ClusterUUID,
+			Version:       root.Version.Number,
+			LuceneVersion: root.Version.LuceneVersion,
+			Distribution:  root.Version.Distribution,
+			Plugins:       map[string]string{},
+		}
+
+		if info.Distribution == "" {
+			info.Distribution = "elasticsearch"
+		}
+
+		for _, p := range plugins {
+			info.Plugins[p.Component] = p.Version
+		}
+
+		return info, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return info.(*ServerInfo), nil
+}
+
+func (s *serverInfoState) rootInfo(ctx context.Context) (*rootResponse, error) {
+	root, err := s.root.get(ctx, func(ctx context.Context) (interface{}, error) {
+		body, err := s.client.Do(ctx, nil, "/", http.MethodGet)
+		if err != nil {
+			return nil, err
+		}
+
+		var root *rootResponse
+
+		err = json.Unmarshal(body, &root)
+		if err != nil {
+			return nil, err
+		}
+		if root == nil {
+			return nil, fmt.Errorf("empty response of the root endpoint")
+		}
+
+		return root, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return root.(*rootResponse), nil
+}
+
+// distribution returns the distribution reported by the root endpoint
+func (s *serverInfoState) distribution(ctx context.Context) (string, error) {
+	root, err := s.rootInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return root.Version.Distribution, nil
+}
+
+// unsupported returns an error wrapping common.ErrUnsupported if a request failed because the endpoint
+// is not available on the cluster, either because its plugin is not installed or because the version
+// of the plugin does not provide it
+func (s *serverInfoState) unsupported(ctx context.Context, endpoint string, statusCode int, body []byte) error {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusMethodNotAllowed:
+	default:
+		return nil
+	}
+
+	if re := common.NewResponseError(statusCode, body); re != nil && strings.HasPrefix(re.Reason, "no handler found for uri") {
+		return fmt.Errorf("%s: %w", re.Reason, common.ErrUnsupported)
+	}
+
+	plugin := pluginAPI(endpoint)
+	if plugin == "" {
+		return nil
+	}
+
+	// a failed check is ignored, the error of the request is returned instead and the check is skipped
+	// for a while instead of repeating the requests of the server info with every failed request
+	s.mu.Lock()
+	skip := time.Since(s.checkFailed) < unsupportedCheckBackoff
+	s.mu.Unlock()
+
+	if skip {
+		return nil
+	}
+
+	info, err := s.serverInfo(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.mu.Lock()
+			s.checkFailed = time.Now()
+			s.mu.Unlock()
+		}
+
+		return nil
+	}
+
+	if info.HasPlugin(plugin) {
+		return nil
+	}
+
+	return fmt.Errorf("%s plugin is not installed: %w", plugin, common.ErrUnsupported)
+}
+
+// pluginAPI returns the plugin serving an endpoint, or an empty string for endpoints of the core
+func pluginAPI(endpoint string) string {
+	for _, prefix := range []string{openDistroPrefix, pluginsPrefix} {
+		if !strings.HasPrefix(endpoint, prefix) {
+			continue
+		}
+
+		api := strings.TrimPrefix(endpoint, prefix)
+		if i := strings.IndexAny(api, "/?"); i >= 0 {
+			api = api[:i]
+		}
+
+		return pluginAPIs[api]
+	}
+
+	return ""
+}
+
+// pluginName normalizes a plugin component name, f.e. "opendistro_security" and "opensearch-security"
+// to "security"
+func pluginName(component string) string {
+	name := strings.Replace(strings.ToLower(component), "_", "-", -1)
+
+	for _, prefix := range []string{"opendistro-", "opensearch-"} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimPrefix(name, prefix)
+		}
+	}
+
+	return name
+}
+
+// get returns the cached value or fetches it, only one fetch runs at a time
+func (f *flight) get(ctx context.Context, fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	f.mu.Lock()
+
+	if f.value != nil {
+		value := f.value
+		f.mu.Unlock()
+
+		return value, nil
+	}
+
+	if c := f.call; c != nil {
+		f.mu.Unlock()
+
+		select {
+		case <-c.done:
+			return c.value, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c := &flightCall{done: make(chan struct{})}
+	f.call = c
+	f.mu.Unlock()
+
+	c.value, c.err = fetch(ctx)
+
+	f.mu.Lock()
+	if c.err == nil {
+		f.value = c.value
+	}
+	f.call = nil
+	f.mu.Unlock()
+
+	close(c.done)
+
+	return c.value, c.err
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newInfoClient returns a client of a cluster without the k-NN plugin whose plugin APIs respond with 404,
+// the cat plugins API responds with the given status
+func newInfoClient(t *testing.T, catStatus *int32, catRequests *int32) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"name":"node-1","version":{"number":"7.10.2"}}`)
+		case "/_cat/plugins":
+			atomic.AddInt32(catRequests, 1)
+			if status := atomic.LoadInt32(catStatus); status != http.StatusOK {
+				w.WriteHeader(int(status))
+				fmt.Fprint(w, `{"error":{"type":"security_exception","reason":"no permissions"},"status":403}`)
+				return
+			}
+			fmt.Fprint(w, `[{"name":"node-1","component":"opendistro_security","version":"1.13.0"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"type":"resource_not_found_exception","reason":"missing"},"status":404}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	client.Client.Logger = nil
+
+	return client
+}
+
+func TestUnsupportedPlugin(t *testing.T) {
+	catStatus, catRequests := int32(http.StatusOK), int32(0)
+	client := newInfoClient(t, &catStatus, &catRequests)
+
+	_, err := client.Do(context.Background(), nil, "/_opendistro/_knn/stats", http.MethodGet)
+	if !errors.Is(err, common.ErrUnsupported) {
+		t.Errorf("request of a missing plugin = %v, want %v", err, common.ErrUnsupported)
+	}
+
+	_, err = client.Do(context.Background(), nil, "/_opendistro/_security/api/roles/missing", http.MethodGet)
+	if err == nil || errors.Is(err, common.ErrUnsupported) {
+		t.Errorf("request of an installed plugin = %v, want the error of the response", err)
+	}
+}
+
+func TestUnsupportedCheckFailed(t *testing.T) {
+	catStatus, catRequests := int32(http.StatusForbidden), int32(0)
+	client := newInfoClient(t, &catStatus, &catRequests)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Do(context.Background(), nil, "/_opendistro/_knn/stats", http.MethodGet)
+		if err == nil || errors.Is(err, common.ErrUnsupported) {
+			t.Errorf("request %d = %v, want the error of the response", i, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&catRequests); n != 1 {
+		t.Errorf("cat plugins requests = %d, want the failed check not to be repeated", n)
+	}
+
+	// the check is repeated after the backoff
+	atomic.StoreInt32(&catStatus, http.StatusOK)
+	client.info.mu.Lock()
+	client.info.checkFailed = time.Now().Add(-unsupportedCheckBackoff)
+	client.info.mu.Unlock()
+
+	_, err := client.Do(context.Background(), nil, "/_opendistro/_knn/stats", http.MethodGet)
+	if !errors.Is(err, common.ErrUnsupported) {
+		t.Errorf("request after the backoff = %v, want %v", err, common.ErrUnsupported)
+	}
+}
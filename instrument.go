@@ -0,0 +1,144 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+type attemptsKey struct{}
+
+// instrumentations calls multiple instrumentations in order and their end functions in reverse order
+type instrumentations []common.Instrumentation
+
+// instrumentedTransport reports every HTTP attempt of a request to the instrumentations
+type instrumentedTransport struct {
+	next            http.RoundTripper
+	instrumentation common.Instrumentation
+}
+
+func (i instrumentations) StartOperation(ctx context.Context, operation *common.OperationInfo) (context.Context, func()) {
+	ends := make([]func(), 0, len(i))
+
+	for _, inst := range i {
+		var end func()
+
+		ctx, end = inst.StartOperation(ctx, operation)
+		ends = append(ends, end)
+	}
+
+	return ctx, func() {
+		for n := len(ends) - 1; n >= 0; n-- {
+			ends[n]()
+		}
+	}
+}
+
+func (i instrumentations) StartRequest(ctx context.Context, request *common.RequestInfo) (context.Context, func(response *common.ResponseInfo)) {
+	ends := make([]func(response *common.ResponseInfo), 0, len(i))
+
+	for _, inst := range i {
+		var end func(response *common.ResponseInfo)
+
+		ctx, end = inst.StartRequest(ctx, request)
+		ends = append(ends, end)
+	}
+
+	return ctx, func(response *common.ResponseInfo) {
+		for n := len(ends) - 1; n >= 0; n-- {
+			ends[n](response)
+		}
+	}
+}
+
+func (i instrumentations) StartAttempt(req *http.Request, attempt int) (*http.Request, func(resp *http.Response, err error)) {
+	ends := make([]func(resp *http.Response, err error), 0, len(i))
+
+	for _, inst := range i {
+		var end func(resp *http.Response, err error)
+
+		req, end = inst.StartAttempt(req, attempt)
+		ends = append(ends, end)
+	}
+
+	return req, func(resp *http.Response, err error) {
+		for n := len(ends) - 1; n >= 0; n-- {
+			ends[n](resp, err)
+		}
+	}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempt := 0
+	if attempts, ok := req.Context().Value(attemptsKey{}).(*int32); ok {
+		attempt = int(atomic.AddInt32(attempts, 1)) - 1
+	}
+
+	req, end := t.instrumentation.StartAttempt(req, attempt)
+
+	resp, err := t.next.RoundTrip(req)
+
+	end(resp, err)
+
+	return resp, err
+}
+
+// StartOperation is called by the service methods, it tags the requests sent with the returned context
+// with the operation and reports the method to the instrumentations. The returned function has to be
+// called when the method returns.
+func (c *Client) StartOperation(ctx context.Context, operation string) (context.Context, func()) {
+	ctx = common.WithOperation(ctx, operation)
+
+	if c.instrumentation == nil {
+		return ctx, func() {}
+	}
+
+	return c.instrumentation.StartOperation(ctx, &common.OperationInfo{
+		Operation: operation,
+		Func:      callerFunc(2),
+	})
+}
+
+// callerFunc returns the name of a calling method without its package, f.e. "RoleService.Create"
+func callerFunc(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return ""
+	}
+
+	name := f.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return strings.NewReplacer("(*", "", "(", "", ")", "").Replace(name)
+}
+
+// startRequest reports a request to the instrumentations, the context counts the HTTP attempts of it
+func (c *Client) startRequest(ctx context.Context, request *common.RequestInfo) (context.Context, func(response *common.ResponseInfo)) {
+	if c.instrumentation == nil {
+		return ctx, func(*common.ResponseInfo) {}
+	}
+
+	request.Operation = common.OperationFromContext(ctx)
+
+	ctx = context.WithValue(ctx, attemptsKey{}, new(int32))
+
+	return c.instrumentation.StartRequest(ctx, request)
+}
@@ -0,0 +1,344 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package knn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strings"
+)
+
+type KNNService common.Service
+
+var errIndexNameRequired = errors.New("index name is required")
+
+type KNNServiceInterface interface {
+	CreateIndex(ctx context.Context, name string, index *Index) error
+	IndexVector(ctx context.Context, index string, id string, document interface{}) error
+	Search(ctx context.Context, index string, query *Query) (*SearchResponse, error)
+	Warmup(ctx context.Context, indices ...string) (*WarmupResponse, error)
+	Stats(ctx context.Context, nodeIDs []string, stats ...string) (*Stats, error)
+}
+
+// Index describes a k-NN enabled index. Vectors are mapped as knn_vector fields, Properties holds the
+// mapping of all other fields.
+type Index struct {
+	Shards   int
+	Replicas *int
+
+	// EFSearch is the size of the dynamic list used during k-NN searches (index.knn.algo_param.ef_search)
+	EFSearch int
+
+	Vectors    map[string]*VectorField
+	Properties map[string]interface{}
+}
+
+type VectorField struct {
+	Type      string  `json:"type"`
+	Dimension int     `json:"dimension"`
+	Method    *Method `json:"method,omitempty"`
+}
+
+// Method configures the approximate k-NN algorithm of a field, f.e. name "hnsw", space type "l2" or
+// "cosinesimil", engine "nmslib" or "faiss" and parameters like "ef_construction" and "m".
+type Method struct {
+	Name       string                 `json:"name"`
+	SpaceType  string                 `json:"space_type,omitempty"`
+	Engine     string                 `json:"engine,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type createIndexRequest struct {
+	Settings indexSettings `json:"settings"`
+	Mappings indexMappings `json:"mappings"`
+}
+
+type indexSettings struct {
+	KNN      bool `json:"index.knn"`
+	EFSearch int  `json:"index.knn.algo_param.ef_search,omitempty"`
+	Shards   int  `json:"index.number_of_shards,omitempty"`
+	Replicas *int `json:"index.number_of_replicas,omitempty"`
+}
+
+type indexMappings struct {
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Query finds the K nearest neighbours of a vector. If a filter query is given, it is applied to the
+// neighbours found (post filtering), so less than K hits might be returned.
+type Query struct {
+	Field  string
+	Vector []float32
+	K      int
+
+	// Size is the number of hits returned, it defaults to K
+	Size   int
+	Filter interface{}
+	Source interface{}
+}
+
+type SearchResponse struct {
+	Took     int64 `json:"took"`
+	TimedOut bool  `json:"timed_out"`
+	Hits     Hits  `json:"hits"`
+}
+
+type Hits struct {
+	Total    Total   `json:"total"`
+	MaxScore float64 `json:"max_score"`
+	Hits     []Hit   `json:"hits"`
+}
+
+type Total struct {
+	Value    int64  `json:"value"`
+	Relation string `json:"relation"`
+}
+
+type Hit struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Score  float64         `json:"_score"`
+	Source json.RawMessage `json:"_source"`
+}
+
+type WarmupResponse struct {
+	Shards Shards `json:"_shards"`
+}
+
+type Shards struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Failed     int `json:"failed"`
+}
+
+type Stats struct {
+	Nodes                   map[string]NodeStats `json:"nodes"`
+	ClusterName             string               `json:"cluster_name"`
+	CircuitBreakerTriggered bool                 `json:"circuit_breaker_triggered"`
+	NodeCounts              Shards               `json:"_nodes"`
+}
+
+type NodeStats struct {
+	GraphMemoryUsage           int64                      `json:"graph_memory_usage"`
+	GraphMemoryUsagePercentage float64                    `json:"graph_memory_usage_percentage"`
+	GraphQueryRequests         int64                      `json:"graph_query_requests"`
+	GraphQueryErrors           int64                      `json:"graph_query_errors"`
+	GraphIndexRequests         int64                      `json:"graph_index_requests"`
+	GraphIndexErrors           int64                      `json:"graph_index_errors"`
+	KNNQueryRequests           int64                      `json:"knn_query_requests"`
+	CacheCapacityReached       bool                       `json:"cache_capacity_reached"`
+	HitCount                   int64                      `json:"hit_count"`
+	MissCount                  int64                      `json:"miss_count"`
+	EvictionCount              int64                      `json:"eviction_count"`
+	LoadSuccessCount           int64                      `json:"load_success_count"`
+	LoadExceptionCount         int64                      `json:"load_exception_count"`
+	TotalLoadTime              int64                      `json:"total_load_time"`
+	ScriptCompilations         int64                      `json:"script_compilations"`
+	ScriptQueryRequests        int64                      `json:"script_query_requests"`
+	ScriptQueryErrors          int64                      `json:"script_query_errors"`
+	IndicesInCache             map[string]IndexCacheStats `json:"indices_in_cache"`
+}
+
+type IndexCacheStats struct {
+	GraphMemoryUsage           int64   `json:"graph_memory_usage"`
+	GraphMemoryUsagePercentage float64 `json:"graph_memory_usage_percentage"`
+	GraphCount                 int64   `json:"graph_count"`
+}
+
+// NewVectorField returns the mapping of a knn_vector field
+func NewVectorField(dimension int, method *Method) *VectorField {
+	return &VectorField{
+		Type:      "knn_vector",
+		Dimension: dimension,
+		Method:    method,
+	}
+}
+
+// Create an index with k-NN enabled
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/knn/approximate-knn/
+func (s *KNNService) CreateIndex(ctx context.Context, name string, index *Index) error {
+	ctx, done := s.Client.StartOperation(ctx, "knn.create_index")
+	defer done()
+
+	if name == "" {
+		return errIndexNameRequired
+	}
+	if index == nil {
+		return errors.New("index is required")
+	}
+
+	properties := map[string]interface{}{}
+
+	for field, mapping := range index.Properties {
+		properties[field] = mapping
+	}
+
+	for field, vector := range index.Vectors {
+		if vector == nil {
+			return fmt.Errorf("vector field %s is nil", field)
+		}
+
+		// the type is defaulted on a copy, the fields of the caller are not modified
+		v := *vector
+		if v.Type == "" {
+			v.Type = "knn_vector"
+		}
+		properties[field] = &v
+	}
+
+	request := &createIndexRequest{
+		Settings: indexSettings{
+			KNN:      true,
+			EFSearch: index.EFSearch,
+			Shards:   index.Shards,
+			Replicas: index.Replicas,
+		},
+		Mappings: indexMappings{Properties: properties},
+	}
+
+	_, err := s.Client.Do(ctx, request, "/"+name, http.MethodPut)
+
+	return err
+}
+
+// IndexVector indexes a document containing one or more vector fields
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/knn/approximate-knn/
+func (s *KNNService) IndexVector(ctx context.Context, index string, id string, document interface{}) error {
+	ctx, done := s.Client.StartOperation(ctx, "knn.index_vector")
+	defer done()
+
+	if index == "" {
+		return errIndexNameRequired
+	}
+	if id == "" {
+		return errors.New("document id is required")
+	}
+
+	endpoint := "/" + index + "/_doc/" + id
+
+	_, err := s.Client.Do(ctx, document, endpoint, http.MethodPut)
+
+	return err
+}
+
+// Search the nearest neighbours of a vector
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/knn/approximate-knn/
+func (s *KNNService) Search(ctx context.Context, index string, query *Query) (*SearchResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "knn.search")
+	defer done()
+
+	if index == "" {
+		return nil, errIndexNameRequired
+	}
+	if query == nil {
+		return nil, errors.New("query is required")
+	}
+	if query.Field == "" {
+		return nil, errors.New("query field is required")
+	}
+
+	endpoint := "/" + index + "/_search"
+
+	size := query.Size
+	if size == 0 {
+		size = query.K
+	}
+
+	var q interface{} = map[string]interface{}{
+		"knn": map[string]interface{}{
+			query.Field: map[string]interface{}{
+				"vector": query.Vector,
+				"k":      query.K,
+			},
+		},
+	}
+
+	if query.Filter != nil {
+		q = map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must":   []interface{}{q},
+				"filter": query.Filter,
+			},
+		}
+	}
+
+	request := map[string]interface{}{
+		"size":  size,
+		"query": q,
+	}
+
+	if query.Source != nil {
+		request["_source"] = query.Source
+	}
+
+	var response *SearchResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, request, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Warmup loads the graphs of the indices into memory
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/knn/api/#warmup-operation
+func (s *KNNService) Warmup(ctx context.Context, indices ...string) (*WarmupResponse, error) {
+	ctx, done := s.Client.StartOperation(ctx, "knn.warmup")
+	defer done()
+
+	if len(indices) == 0 {
+		return nil, errors.New("warmup requires at least one index")
+	}
+
+	endpoint := common.KNNEndpoint + "/warmup/" + strings.Join(indices, ",")
+
+	var response *WarmupResponse
+
+	err := s.Client.Get(ctx, endpoint, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// Stats returns the statistics of the plugin, optionally restricted to nodes and statistic names
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/knn/api/#stats
+func (s *KNNService) Stats(ctx context.Context, nodeIDs []string, stats ...string) (*Stats, error) {
+	ctx, done := s.Client.StartOperation(ctx, "knn.stats")
+	defer done()
+
+	endpoint := common.KNNEndpoint
+
+	if len(nodeIDs) > 0 {
+		endpoint = endpoint + "/" + strings.Join(nodeIDs, ",")
+	}
+
+	endpoint = endpoint + "/stats"
+
+	if len(stats) > 0 {
+		endpoint = endpoint + "/" + strings.Join(stats, ",")
+	}
+
+	var response *Stats
+
+	err := s.Client.Get(ctx, endpoint, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
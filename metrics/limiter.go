@@ -0,0 +1,65 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"github.com/WhizUs/go-opendistro"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LimiterSource provides the stats of the rate limits by endpoint class, it is implemented by
+// opendistro.Client
+type LimiterSource interface {
+	LimiterStats() map[string]opendistro.LimiterStats
+}
+
+// limiterCollector exports the stats of the rate limits of a client when the metrics are scraped
+type limiterCollector struct {
+	source        LimiterSource
+	requests      *prometheus.Desc
+	queued        *prometheus.Desc
+	queuedTime    *prometheus.Desc
+	maxQueuedTime *prometheus.Desc
+}
+
+// RegisterLimiters registers the stats of the rate limits of a client, labeled by the endpoint class
+// (opendistro.GlobalLimiter for the limit of all requests). The client is created with the collector,
+// so its limiters are registered afterwards, f.e.
+//
+//	collector, err := metrics.NewCollector(prometheus.DefaultRegisterer, nil)
+//	client, err := opendistro.NewClient(&opendistro.ClientConfig{Instrumentations: []common.Instrumentation{collector}})
+//	err = collector.RegisterLimiters(client)
+func (c *Collector) RegisterLimiters(source LimiterSource) error {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(c.namespace, "rate_limit", name), help, []string{"class"}, c.constLabels)
+	}
+
+	return c.registerer.Register(&limiterCollector{
+		source:        source,
+		requests:      desc("requests_total", "Number of requests passed by the rate limit."),
+		queued:        desc("queued_requests_total", "Number of requests which waited for the rate limit."),
+		queuedTime:    desc("queued_seconds_total", "Total time requests waited for the rate limit."),
+		maxQueuedTime: desc("max_queued_seconds", "Longest time a request waited for the rate limit."),
+	})
+}
+
+// Describe implements prometheus.Collector
+func (l *limiterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.requests
+	ch <- l.queued
+	ch <- l.queuedTime
+	ch <- l.maxQueuedTime
+}
+
+// Collect implements prometheus.Collector
+func (l *limiterCollector) Collect(ch chan<- prometheus.Metric) {
+	for class, stats := range l.source.LimiterStats() {
+		ch <- prometheus.MustNewConstMetric(l.requests, prometheus.CounterValue, float64(stats.Requests), class)
+		ch <- prometheus.MustNewConstMetric(l.queued, prometheus.CounterValue, float64(stats.Queued), class)
+		ch <- prometheus.MustNewConstMetric(l.queuedTime, prometheus.CounterValue, stats.QueuedTime.Seconds(), class)
+		ch <- prometheus.MustNewConstMetric(l.maxQueuedTime, prometheus.GaugeValue, stats.MaxQueuedTime.Seconds(), class)
+	}
+}
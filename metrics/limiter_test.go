@@ -0,0 +1,78 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics_test
+
+import (
+	"github.com/WhizUs/go-opendistro"
+	"github.com/WhizUs/go-opendistro/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"testing"
+	"time"
+)
+
+var _ metrics.LimiterSource = (*opendistro.Client)(nil)
+
+type limiterSource map[string]opendistro.LimiterStats
+
+func (s limiterSource) LimiterStats() map[string]opendistro.LimiterStats {
+	return s
+}
+
+func TestRegisterLimiters(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	collector, err := metrics.NewCollector(registry, &metrics.Options{ConstLabels: prometheus.Labels{"cluster": "test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = collector.RegisterLimiters(limiterSource{
+		opendistro.GlobalLimiter: {Requests: 10, Queued: 2, QueuedTime: 1500 * time.Millisecond, MaxQueuedTime: time.Second},
+		"bulk":                   {Requests: 4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]float64{}
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			labels := map[string]string{}
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["cluster"] != "test" {
+				t.Errorf("%s has labels %v, want the const labels", family.GetName(), labels)
+			}
+
+			value := metric.GetCounter().GetValue()
+			if metric.Gauge != nil {
+				value = metric.GetGauge().GetValue()
+			}
+
+			values[family.GetName()+"/"+labels["class"]] = value
+		}
+	}
+
+	for name, want := range map[string]float64{
+		"opendistro_client_rate_limit_requests_total/global":        10,
+		"opendistro_client_rate_limit_queued_requests_total/global": 2,
+		"opendistro_client_rate_limit_queued_seconds_total/global":  1.5,
+		"opendistro_client_rate_limit_max_queued_seconds/global":    1,
+		"opendistro_client_rate_limit_requests_total/bulk":          4,
+		"opendistro_client_rate_limit_queued_seconds_total/bulk":    0,
+	} {
+		if got, ok := values[name]; !ok || got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"context"
+	"github.com/WhizUs/go-opendistro/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultNamespace = "opendistro_client"
+
+	otherLabel = "other"
+	errorLabel = "error"
+)
+
+type Options struct {
+	// Namespace of the metrics, defaults to DefaultNamespace
+	Namespace string
+
+	// Buckets of the latency histogram in seconds, defaults to prometheus.DefBuckets
+	Buckets []float64
+
+	// ConstLabels are added to all metrics, f.e. the name of the cluster
+	ConstLabels prometheus.Labels
+}
+
+// Collector collects the metrics of the requests of a client, it is passed to the client in
+// ClientConfig.Instrumentations. Requests are labeled by the service and the operation which sent them
+// (f.e. "security.users" and "create"), requests which were not sent by a service are labeled "other".
+type Collector struct {
+	registerer  prometheus.Registerer
+	namespace   string
+	constLabels prometheus.Labels
+
+	requests      *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+	bytesSent     *prometheus.CounterVec
+	bytesReceived *prometheus.CounterVec
+	inFlight      *prometheus.GaugeVec
+}
+
+// NewCollector creates the metrics and registers them
+func NewCollector(registerer prometheus.Registerer, options *Options) (*Collector, error) {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = prometheus.DefBuckets
+	}
+
+	labels := []string{"service", "operation"}
+
+	c := &Collector{
+		registerer:  registerer,
+		namespace:   opts.Namespace,
+		constLabels: opts.ConstLabels,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "requests_total",
+			Help:        "Number of requests by service, operation, method and status code.",
+			ConstLabels: opts.ConstLabels,
+		}, append(labels, "method", "status")),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   opts.Namespace,
+			Name:        "request_duration_seconds",
+			Help:        "Latency of requests including retries.",
+			ConstLabels: opts.ConstLabels,
+			Buckets:     opts.Buckets,
+		}, labels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "retries_total",
+			Help:        "Number of retried HTTP attempts.",
+			ConstLabels: opts.ConstLabels,
+		}, labels),
+		bytesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "sent_bytes_total",
+			Help:        "Size of the request bodies.",
+			ConstLabels: opts.ConstLabels,
+		}, labels),
+		bytesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   opts.Namespace,
+			Name:        "received_bytes_total",
+			Help:        "Size of the response bodies.",
+			ConstLabels: opts.ConstLabels,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:   opts.Namespace,
+			Name:        "requests_in_flight",
+			Help:        "Number of requests waiting for a response.",
+			ConstLabels: opts.ConstLabels,
+		}, labels),
+	}
+
+	for _, collector := range []prometheus.Collector{c.requests, c.duration, c.retries, c.bytesSent, c.bytesReceived, c.inFlight} {
+		err := registerer.Register(collector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// StartOperation implements common.Instrumentation, the metrics are collected per request
+func (c *Collector) StartOperation(ctx context.Context, operation *common.OperationInfo) (context.Context, func()) {
+	return ctx, func() {}
+}
+
+// StartRequest implements common.Instrumentation
+func (c *Collector) StartRequest(ctx context.Context, request *common.RequestInfo) (context.Context, func(response *common.ResponseInfo)) {
+	service, operation := splitOperation(request.Operation)
+
+	start := time.Now()
+
+	c.inFlight.WithLabelValues(service, operation).Inc()
+	c.bytesSent.WithLabelValues(service, operation).Add(float64(request.BytesSent))
+
+	return ctx, func(response *common.ResponseInfo) {
+		c.inFlight.WithLabelValues(service, operation).Dec()
+		c.duration.WithLabelValues(service, operation).Observe(time.Since(start).Seconds())
+		c.bytesReceived.WithLabelValues(service, operation).Add(float64(response.BytesReceived))
+
+		status := errorLabel
+		if response.Err == nil {
+			status = strconv.Itoa(response.StatusCode)
+		}
+
+		c.requests.WithLabelValues(service, operation, request.Method, status).Inc()
+	}
+}
+
+// StartAttempt implements common.Instrumentation
+func (c *Collector) StartAttempt(req *http.Request, attempt int) (*http.Request, func(resp *http.Response, err error)) {
+	if attempt > 0 {
+		service, operation := splitOperation(common.OperationFromContext(req.Context()))
+
+		c.retries.WithLabelValues(service, operation).Inc()
+	}
+
+	return req, func(*http.Response, error) {}
+}
+
+// splitOperation splits an operation like "security.users.create" into the service "security.users" and
+// the operation "create"
+func splitOperation(operation string) (string, string) {
+	if operation == "" {
+		return otherLabel, otherLabel
+	}
+
+	i := strings.LastIndex(operation, ".")
+	if i < 0 {
+		return operation, operation
+	}
+
+	return operation[:i], operation[i+1:]
+}
@@ -0,0 +1,133 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package performanceanalyzer
+
+import (
+	"context"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type MetricsService common.Service
+
+type MetricsServiceInterface interface {
+	Query(ctx context.Context, query *MetricsQuery) (map[string]*NodeMetrics, error)
+}
+
+// MetricsQuery selects metrics (f.e. "CPU_Utilization") aggregated by one aggregation per metric (avg, sum,
+// min or max) and grouped by dimensions (f.e. "ShardID", "IndexName").
+type MetricsQuery struct {
+	Metrics      []string
+	Aggregations []string
+	Dimensions   []string
+
+	// AllNodes queries the metrics of all nodes instead of the local node only
+	AllNodes bool
+}
+
+// NodeMetrics is the table of metrics of one node for the last sampling period
+type NodeMetrics struct {
+	Timestamp int64 `json:"timestamp"`
+	Data      Table `json:"data"`
+}
+
+type Table struct {
+	Fields  []Field         `json:"fields"`
+	Records [][]interface{} `json:"records"`
+}
+
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Record is a row of a table with its dimensions and metric values by name
+type Record struct {
+	Dimensions map[string]string
+	Metrics    map[string]float64
+}
+
+// Query the metrics of the last sampling period, by node id
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/pa/api/
+func (s *MetricsService) Query(ctx context.Context, query *MetricsQuery) (map[string]*NodeMetrics, error) {
+	ctx, done := s.Client.StartOperation(ctx, "performance_analyzer.metrics.query")
+	defer done()
+
+	if query == nil {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	if len(query.Metrics) == 0 {
+		return nil, fmt.Errorf("at least one metric is required")
+	}
+
+	if len(query.Aggregations) != len(query.Metrics) {
+		return nil, fmt.Errorf("%d aggregations given for %d metrics", len(query.Aggregations), len(query.Metrics))
+	}
+
+	params := url.Values{}
+	params.Set("metrics", strings.Join(query.Metrics, ","))
+	params.Set("agg", strings.Join(query.Aggregations, ","))
+
+	if len(query.Dimensions) > 0 {
+		params.Set("dim", strings.Join(query.Dimensions, ","))
+	}
+
+	if query.AllNodes {
+		params.Set("nodes", "all")
+	}
+
+	endpoint := common.PerformanceAnalyzerMetricsEndpoint + "?" + params.Encode()
+
+	var metrics map[string]*NodeMetrics
+
+	err := s.Client.Get(ctx, endpoint, &metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
+// Time returns the end of the sampling period
+func (m *NodeMetrics) Time() time.Time {
+	return time.Unix(0, m.Timestamp*int64(time.Millisecond))
+}
+
+// Rows converts the records of the table. Fields of type DOUBLE are metrics, all others dimensions.
+func (t *Table) Rows() []Record {
+	records := make([]Record, 0, len(t.Records))
+
+	for _, row := range t.Records {
+		record := Record{
+			Dimensions: map[string]string{},
+			Metrics:    map[string]float64{},
+		}
+
+		for i, field := range t.Fields {
+			if i >= len(row) || row[i] == nil {
+				continue
+			}
+
+			if strings.EqualFold(field.Type, "DOUBLE") {
+				if v, ok := row[i].(float64); ok {
+					record.Metrics[field.Name] = v
+				}
+				continue
+			}
+
+			record.Dimensions[field.Name] = fmt.Sprint(row[i])
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
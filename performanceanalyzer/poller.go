@@ -0,0 +1,61 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package performanceanalyzer
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultPollInterval is the interval the metrics are polled with if none is given, the performance
+// analyzer aggregates its metrics in 5 second windows
+const DefaultPollInterval = 5 * time.Second
+
+// Sample is the result of one poll, either the metrics by node id or the error of the query
+type Sample struct {
+	Time  time.Time
+	Nodes map[string]*NodeMetrics
+	Err   error
+}
+
+// Poll queries the metrics in the given interval and emits the samples on the returned channel. Errors are
+// emitted as samples as well, so a failing node does not stop the polling. The channel is closed as soon as
+// the context is done. An interval <= 0 defaults to DefaultPollInterval.
+func Poll(ctx context.Context, service MetricsServiceInterface, query *MetricsQuery, interval time.Duration) <-chan Sample {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	samples := make(chan Sample)
+
+	go func() {
+		defer close(samples)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			nodes, err := service.Query(ctx, query)
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case samples <- Sample{Time: time.Now(), Nodes: nodes, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return samples
+}
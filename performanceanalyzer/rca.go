@@ -0,0 +1,83 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package performanceanalyzer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/url"
+)
+
+type RCAService common.Service
+
+type RCAServiceInterface interface {
+	Get(ctx context.Context, name string) (map[string][]RCA, error)
+}
+
+// RCA is the latest result of a root cause analysis. The summaries (f.e. "HotClusterSummary") differ
+// between the analyses and are kept as raw JSON by name.
+type RCA struct {
+	Name      string
+	State     string
+	Timestamp int64
+	Summaries map[string]json.RawMessage
+}
+
+// Get the results of a root cause analysis by name (f.e. "HighHeapUsageClusterRca"), all results are
+// returned if no name is given
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/rca/api/
+func (s *RCAService) Get(ctx context.Context, name string) (map[string][]RCA, error) {
+	ctx, done := s.Client.StartOperation(ctx, "performance_analyzer.rca.get")
+	defer done()
+
+	endpoint := common.PerformanceAnalyzerRCAEndpoint
+
+	if name != "" {
+		endpoint = endpoint + "?name=" + url.QueryEscape(name)
+	}
+
+	var rcas map[string][]RCA
+
+	err := s.Client.Get(ctx, endpoint, &rcas)
+	if err != nil {
+		return nil, err
+	}
+
+	return rcas, nil
+}
+
+func (r *RCA) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*r = RCA{Summaries: map[string]json.RawMessage{}}
+
+	for key, value := range fields {
+		var err error
+
+		switch key {
+		case "rca_name":
+			err = json.Unmarshal(value, &r.Name)
+		case "state":
+			err = json.Unmarshal(value, &r.State)
+		case "timestamp":
+			err = json.Unmarshal(value, &r.Timestamp)
+		default:
+			r.Summaries[key] = value
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
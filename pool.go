@@ -0,0 +1,283 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/cluster"
+	"github.com/WhizUs/go-opendistro/common"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSniffingDisabled is returned by Sniff if the addresses of the client have a path, f.e. the prefix of a
+// proxy the nodes are reachable by
+var ErrSniffingDisabled = errors.New("sniffing is disabled for addresses with a path")
+
+// NodeSelector selects the node a request is sent to if the client is configured with multiple addresses
+type NodeSelector string
+
+const (
+	// RoundRobin sends the requests to the alive nodes in turn
+	RoundRobin NodeSelector = "round_robin"
+
+	// LeastLatency sends the requests to the alive node with the lowest average latency
+	LeastLatency NodeSelector = "least_latency"
+
+	DefaultResurrectTimeout = time.Minute
+
+	maxResurrectTimeout = 30 * time.Minute
+
+	// latencyWeight is the weight of a new sample in the moving average of the latency
+	latencyWeight = 0.2
+)
+
+type node struct {
+	url       string
+	failures  int
+	deadUntil time.Time
+	latency   time.Duration
+}
+
+// nodePool keeps track of the nodes of a cluster. A node is marked dead on connection errors and is
+// retried after a timeout which doubles with every consecutive failure.
+type nodePool struct {
+	mu               sync.Mutex
+	nodes            []*node
+	next             int
+	selector         NodeSelector
+	resurrectTimeout time.Duration
+
+	// prefixed is set if an address has a path (f.e. the prefix of a proxy), the publish addresses of the
+	// nodes do not have it and may not be reachable at all
+	prefixed bool
+}
+
+func newNodePool(urls []string, selector NodeSelector, resurrectTimeout time.Duration) *nodePool {
+	if selector == "" {
+		selector = RoundRobin
+	}
+	if resurrectTimeout <= 0 {
+		resurrectTimeout = DefaultResurrectTimeout
+	}
+
+	p := &nodePool{
+		selector:         selector,
+		resurrectTimeout: resurrectTimeout,
+	}
+
+	for _, u := range urls {
+		u = strings.TrimSuffix(u, "/")
+
+		if parsed, err := url.Parse(u); err == nil && parsed.Path != "" {
+			p.prefixed = true
+		}
+
+		p.nodes = append(p.nodes, &node{url: u})
+	}
+
+	return p
+}
+
+// size returns the number of nodes of the pool
+func (p *nodePool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.nodes)
+}
+
+// pick returns the URL of the node to send the next request to. If all nodes are dead, the node which is
+// retried next is returned.
+func (p *nodePool) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	var alive []*node
+	for _, n := range p.nodes {
+		if !now.Before(n.deadUntil) {
+			alive = append(alive, n)
+		}
+	}
+
+	if len(alive) == 0 {
+		next := p.nodes[0]
+		for _, n := range p.nodes[1:] {
+			if n.deadUntil.Before(next.deadUntil) {
+				next = n
+			}
+		}
+
+		return next.url
+	}
+
+	if p.selector == LeastLatency {
+		best := alive[0]
+		for _, n := range alive[1:] {
+			if n.latency < best.latency {
+				best = n
+			}
+		}
+
+		return best.url
+	}
+
+	n := alive[p.next%len(alive)]
+	p.next++
+
+	return n.url
+}
+
+// markDead excludes a node until its resurrect timeout is over
+func (p *nodePool) markDead(u string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := p.find(u)
+	if n == nil {
+		return
+	}
+
+	timeout := p.resurrectTimeout << uint(n.failures)
+	if timeout > maxResurrectTimeout || timeout <= 0 {
+		timeout = maxResurrectTimeout
+	}
+
+	n.failures++
+	n.deadUntil = time.Now().Add(timeout)
+}
+
+// markAlive resets the failures of a node and records the latency of a request
+func (p *nodePool) markAlive(u string, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := p.find(u)
+	if n == nil {
+		return
+	}
+
+	n.failures = 0
+	n.deadUntil = time.Time{}
+
+	if n.latency == 0 {
+		n.latency = latency
+	} else {
+		n.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(n.latency))
+	}
+}
+
+// update replaces the nodes of the pool, the state of nodes which are known already is kept
+func (p *nodePool) update(urls []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	nodes := make([]*node, 0, len(urls))
+
+	for _, u := range urls {
+		if n := p.find(u); n != nil {
+			nodes = append(nodes, n)
+		} else {
+			nodes = append(nodes, &node{url: u})
+		}
+	}
+
+	p.nodes = nodes
+	p.next = 0
+}
+
+func (p *nodePool) find(u string) *node {
+	for _, n := range p.nodes {
+		if n.url == u {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// Sniff replaces the nodes of the client by the HTTP addresses of the nodes of the cluster. The nodes are
+// addressed with the scheme of the base URL, ErrSniffingDisabled is returned if the addresses have a path.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/cluster-nodes-info.html
+func (c *Client) Sniff(ctx context.Context) error {
+	if c.pool != nil && c.pool.prefixed {
+		return ErrSniffingDisabled
+	}
+
+	var info *cluster.NodesInfo
+
+	err := c.Get(ctx, common.NodesEndpoint+"/http", &info)
+	if err != nil || info == nil {
+		return err
+	}
+
+	scheme := "http"
+	if u, err := url.Parse(c.BaseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+
+	var urls []string
+
+	for _, n := range info.Nodes {
+		if n.HTTP == nil || n.HTTP.PublishAddress == "" {
+			continue
+		}
+
+		urls = append(urls, scheme+"://"+publishHost(n.HTTP.PublishAddress))
+	}
+
+	sort.Strings(urls)
+
+	if len(urls) > 0 && c.pool != nil {
+		c.pool.update(urls)
+	}
+
+	return nil
+}
+
+// sniff updates the nodes in an interval until the client is closed
+func (c *Client) sniff(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		_ = c.Sniff(ctx)
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// publishHost returns the host and port of a publish address, which is either "ip:port" or
+// "hostname/ip:port"
+func publishHost(address string) string {
+	i := strings.Index(address, "/")
+	if i <= 0 {
+		return strings.TrimPrefix(address, "/")
+	}
+
+	hostname := address[:i]
+
+	_, port, err := net.SplitHostPort(address[i+1:])
+	if err != nil {
+		return address[i+1:]
+	}
+
+	return net.JoinHostPort(hostname, port)
+}
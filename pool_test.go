@@ -0,0 +1,231 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewNodePool(t *testing.T) {
+	p := newNodePool([]string{"http://a:9200/", "http://b:9200"}, "", 0)
+
+	if p.selector != RoundRobin || p.resurrectTimeout != DefaultResurrectTimeout {
+		t.Errorf("selector = %s, resurrect timeout = %s, want the defaults", p.selector, p.resurrectTimeout)
+	}
+	if p.nodes[0].url != "http://a:9200" {
+		t.Errorf("url = %s, want the trailing slash trimmed", p.nodes[0].url)
+	}
+
+	for _, test := range []struct {
+		urls     []string
+		prefixed bool
+	}{
+		{[]string{"http://a:9200"}, false},
+		{[]string{"http://a:9200/"}, false},
+		{[]string{"https://a:9200", "https://b:9200"}, false},
+		{[]string{"https://proxy/es"}, true},
+		{[]string{"https://proxy/es/"}, true},
+		{[]string{"http://a:9200", "https://proxy/es"}, true},
+	} {
+		if p := newNodePool(test.urls, RoundRobin, time.Second); p.prefixed != test.prefixed {
+			t.Errorf("prefixed of %v = %t, want %t", test.urls, p.prefixed, test.prefixed)
+		}
+	}
+}
+
+func TestPickRoundRobin(t *testing.T) {
+	p := newNodePool([]string{"a", "b", "c"}, RoundRobin, time.Minute)
+
+	if got := picks(p, 4); got != "[a b c a]" {
+		t.Errorf("picks = %s", got)
+	}
+
+	p.next = 0
+	p.markDead("b")
+
+	if got := picks(p, 4); got != "[a c a c]" {
+		t.Errorf("picks with a dead node = %s", got)
+	}
+}
+
+func TestPickLeastLatency(t *testing.T) {
+	p := newNodePool([]string{"a", "b", "c"}, LeastLatency, time.Minute)
+	p.markAlive("a", 30*time.Millisecond)
+	p.markAlive("b", 10*time.Millisecond)
+	p.markAlive("c", 20*time.Millisecond)
+
+	if got := picks(p, 2); got != "[b b]" {
+		t.Errorf("picks = %s", got)
+	}
+
+	p.markDead("b")
+
+	if got := picks(p, 2); got != "[c c]" {
+		t.Errorf("picks with the fastest node dead = %s", got)
+	}
+}
+
+func TestPickAllDead(t *testing.T) {
+	p := newNodePool([]string{"a", "b", "c"}, RoundRobin, time.Minute)
+
+	// b fails twice, its timeout is doubled and it is retried last
+	p.markDead("b")
+	p.markDead("b")
+	p.markDead("c")
+	p.markDead("a")
+
+	if got := p.pick(); got != "c" {
+		t.Errorf("pick = %s, want the node which is resurrected first", got)
+	}
+}
+
+func TestMarkDeadBackoff(t *testing.T) {
+	p := newNodePool([]string{"a"}, RoundRobin, time.Second)
+	n := p.nodes[0]
+
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		before := time.Now()
+		p.markDead("a")
+
+		if timeout := n.deadUntil.Sub(before); timeout < want || timeout > want+time.Second/2 {
+			t.Errorf("resurrect timeout after %d failures = %s, want %s", n.failures, timeout, want)
+		}
+	}
+
+	// the timeout is capped, also if the shift overflows
+	n.failures = 70
+	p.markDead("a")
+
+	if timeout := time.Until(n.deadUntil); timeout > maxResurrectTimeout || timeout < maxResurrectTimeout-time.Second {
+		t.Errorf("resurrect timeout = %s, want %s", timeout, maxResurrectTimeout)
+	}
+
+	p.markAlive("a", time.Millisecond)
+
+	if n.failures != 0 || !n.deadUntil.IsZero() {
+		t.Errorf("node after markAlive = %+v, want it alive", n)
+	}
+
+	// unknown nodes are ignored
+	p.markDead("x")
+	p.markAlive("x", time.Second)
+}
+
+func TestMarkAliveLatency(t *testing.T) {
+	p := newNodePool([]string{"a"}, LeastLatency, time.Second)
+
+	p.markAlive("a", 100*time.Millisecond)
+	p.markAlive("a", 200*time.Millisecond)
+
+	if latency := p.nodes[0].latency; latency != 120*time.Millisecond {
+		t.Errorf("latency = %s, want the moving average of 120ms", latency)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	p := newNodePool([]string{"a", "b"}, RoundRobin, time.Minute)
+	p.markDead("b")
+	p.pick()
+
+	p.update([]string{"b", "c"})
+
+	if p.size() != 2 || p.next != 0 {
+		t.Fatalf("nodes = %d, next = %d", p.size(), p.next)
+	}
+	if b := p.find("b"); b == nil || b.failures != 1 {
+		t.Errorf("b = %+v, want its failure kept", b)
+	}
+	if p.find("a") != nil {
+		t.Error("a was not removed")
+	}
+	if c := p.find("c"); c == nil || c.failures != 0 {
+		t.Errorf("c = %+v, want a new node", c)
+	}
+}
+
+func TestPublishHost(t *testing.T) {
+	for _, test := range []struct {
+		address string
+		want    string
+	}{
+		{"10.0.0.1:9200", "10.0.0.1:9200"},
+		{"node-1/10.0.0.1:9200", "node-1:9200"},
+		{"/10.0.0.1:9200", "10.0.0.1:9200"},
+		{"[::1]:9200", "[::1]:9200"},
+		{"node-1/[::1]:9200", "node-1:9200"},
+		{"node-1/10.0.0.1", "10.0.0.1"},
+	} {
+		if got := publishHost(test.address); got != test.want {
+			t.Errorf("publishHost(%q) = %s, want %s", test.address, got, test.want)
+		}
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	var failing int32
+	var requests int32
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer up.Close()
+
+	client, err := NewClient(&ClientConfig{Addresses: []string{down.URL, up.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Client.Logger = nil
+	client.Client.RetryMax = 0
+
+	ctx := context.Background()
+
+	// the refused connection fails over to the next node
+	if _, err := client.Do(ctx, nil, "/", http.MethodGet); err != nil {
+		t.Fatalf("do: %s", err)
+	}
+	if n := client.pool.find(down.URL); n.failures != 1 {
+		t.Errorf("failures of the node which is down = %d, want 1", n.failures)
+	}
+
+	// an error response is returned and does not mark the node dead
+	atomic.StoreInt32(&failing, 1)
+	atomic.StoreInt32(&requests, 0)
+
+	if _, err := client.Do(ctx, nil, "/", http.MethodGet); err == nil {
+		t.Fatal("do returned no error for an error response")
+	}
+	if n := client.pool.find(up.URL); n.failures != 0 || !n.deadUntil.IsZero() {
+		t.Errorf("node with an error response = %+v, want it alive", n)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+// picks returns the nodes of n picks
+func picks(p *nodePool, n int) string {
+	var urls []string
+	for i := 0; i < n; i++ {
+		urls = append(urls, p.pick())
+	}
+
+	return fmt.Sprint(urls)
+}
@@ -0,0 +1,269 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GlobalLimiter is the key of the limiter applied to all requests in the limiter stats
+const GlobalLimiter = "global"
+
+// ErrRateLimited is returned if the context deadline of a request is reached before the rate limit allows it
+var ErrRateLimited = errors.New("rate limit wait exceeds context deadline")
+
+// RateLimit limits the requests of the client, zero values disable the respective limit
+type RateLimit struct {
+	// RequestsPerSecond and Burst configure a token bucket, Burst defaults to 1
+	RequestsPerSecond float64
+	Burst             int
+
+	// MaxInFlight caps the number of concurrent requests
+	MaxInFlight int
+}
+
+// LimiterStats are the counters of a limiter, QueuedTime is the total time requests waited for it
+type LimiterStats struct {
+	Requests      uint64
+	Queued        uint64
+	QueuedTime    time.Duration
+	MaxQueuedTime time.Duration
+}
+
+// limiters holds the global limiter and the limiters of the endpoint classes. A request waits for the
+// global limiter first and for the limiter of its class afterwards.
+type limiters struct {
+	global  *limiter
+	classes map[string]*limiter
+}
+
+type limiter struct {
+	bucket *tokenBucket
+	sem    chan struct{}
+
+	mu    sync.Mutex
+	stats LimiterStats
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiters(global *RateLimit, classes map[string]*RateLimit) *limiters {
+	l := &limiters{
+		global:  newLimiter(global),
+		classes: map[string]*limiter{},
+	}
+
+	for class, limit := range classes {
+		l.classes[class] = newLimiter(limit)
+	}
+
+	return l
+}
+
+func newLimiter(limit *RateLimit) *limiter {
+	l := &limiter{}
+
+	if limit == nil {
+		return l
+	}
+
+	if limit.RequestsPerSecond > 0 {
+		burst := float64(limit.Burst)
+		if burst < 1 {
+			burst = 1
+		}
+
+		l.bucket = &tokenBucket{
+			rate:   limit.RequestsPerSecond,
+			burst:  burst,
+			tokens: burst,
+			last:   time.Now(),
+		}
+	}
+
+	if limit.MaxInFlight > 0 {
+		l.sem = make(chan struct{}, limit.MaxInFlight)
+	}
+
+	return l
+}
+
+// acquire waits until the limiters allow a request to the endpoint, the returned function releases the
+// in-flight slots after the request
+func (l *limiters) acquire(ctx context.Context, endpoint string) (func(), error) {
+	var releases []func()
+
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	for _, lim := range []*limiter{l.global, l.classes[endpointClass(endpoint)]} {
+		if lim == nil {
+			continue
+		}
+
+		r, err := lim.acquire(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+
+		releases = append(releases, r)
+	}
+
+	return release, nil
+}
+
+// stats returns the stats of all limiters by class
+func (l *limiters) stats() map[string]LimiterStats {
+	stats := map[string]LimiterStats{
+		GlobalLimiter: l.global.snapshot(),
+	}
+
+	for class, lim := range l.classes {
+		stats[class] = lim.snapshot()
+	}
+
+	return stats
+}
+
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+
+	if l.bucket != nil {
+		err := l.bucket.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	release := func() {}
+
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+			release = func() { <-l.sem }
+		case <-ctx.Done():
+			if l.bucket != nil {
+				l.bucket.refund()
+			}
+
+			return nil, ctx.Err()
+		}
+	}
+
+	l.record(time.Since(start))
+
+	return release, nil
+}
+
+func (l *limiter) record(queued time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Requests++
+
+	// waits below a millisecond are not counted as queued
+	if queued < time.Millisecond {
+		return
+	}
+
+	l.stats.Queued++
+	l.stats.QueuedTime += queued
+	if queued > l.stats.MaxQueuedTime {
+		l.stats.MaxQueuedTime = queued
+	}
+}
+
+func (l *limiter) snapshot() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// wait takes a token, waiting for it if the bucket is empty. The token is returned if the context is
+// done before.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+
+	now := time.Now()
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+
+	if deadline, ok := ctx.Deadline(); ok && delay > 0 && now.Add(delay).After(deadline) {
+		b.tokens++
+		b.mu.Unlock()
+		return ErrRateLimited
+	}
+
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.refund()
+
+		return ctx.Err()
+	}
+}
+
+// refund returns a token taken by a request which is not sent
+func (b *tokenBucket) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens++
+}
+
+// endpointClass returns the class of an endpoint for the rate limits, which is the plugin of a plugin API
+// (f.e. "security") and the first API segment without underscore otherwise (f.e. "bulk", "search",
+// "cluster" or "doc")
+func endpointClass(endpoint string) string {
+	if plugin := pluginAPI(endpoint); plugin != "" {
+		return plugin
+	}
+
+	path := endpoint
+	if i := strings.Index(path, "?"); i >= 0 {
+		path = path[:i]
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "_") {
+			return strings.TrimPrefix(segment, "_")
+		}
+	}
+
+	return ""
+}
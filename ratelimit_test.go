@@ -0,0 +1,212 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package opendistro
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	l := newLimiter(&RateLimit{RequestsPerSecond: 20, Burst: 2})
+	ctx := context.Background()
+
+	start := time.Now()
+
+	// the burst is allowed at once, the next request waits for a token
+	for i := 0; i < 3; i++ {
+		release, err := l.acquire(ctx)
+		if err != nil {
+			t.Fatalf("acquire %d: %s", i, err)
+		}
+		release()
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("three requests took %s, want about 50ms", elapsed)
+	}
+
+	stats := l.snapshot()
+	if stats.Requests != 3 || stats.Queued != 1 || stats.QueuedTime < 40*time.Millisecond || stats.MaxQueuedTime != stats.QueuedTime {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestTokenBucketDeadline(t *testing.T) {
+	l := newLimiter(&RateLimit{RequestsPerSecond: 1})
+
+	if _, err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the wait for the next token exceeds the deadline, the request fails at once
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	if _, err := l.acquire(ctx); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("acquire = %v, want %v", err, ErrRateLimited)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("acquire failed after %s, want it to fail at once", elapsed)
+	}
+
+	assertTokens(t, l.bucket, 0)
+
+	// a canceled wait returns its token
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if _, err := l.acquire(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("acquire = %v, want %v", err, context.Canceled)
+	}
+
+	assertTokens(t, l.bucket, 0)
+
+	if stats := l.snapshot(); stats.Requests != 1 {
+		t.Errorf("requests = %d, want the failed requests not counted", stats.Requests)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	l := newLimiter(&RateLimit{MaxInFlight: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire of a full limiter = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	time.AfterFunc(20*time.Millisecond, release)
+
+	second, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire after the release: %s", err)
+	}
+	second()
+
+	if stats := l.snapshot(); stats.Requests != 2 || stats.Queued != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestMaxInFlightRefundsToken(t *testing.T) {
+	l := newLimiter(&RateLimit{RequestsPerSecond: 1, Burst: 2, MaxInFlight: 1})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// the token of a request which does not get an in-flight slot is returned
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	assertTokens(t, l.bucket, 1)
+}
+
+func TestLimiters(t *testing.T) {
+	l := newLimiters(&RateLimit{MaxInFlight: 2}, map[string]*RateLimit{"bulk": {MaxInFlight: 1}})
+	ctx := context.Background()
+
+	bulk, err := l.acquire(ctx, "/_bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the bulk class is full, the global slot taken before is released again
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.acquire(timeout, "/books/_bulk"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire of a full class = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := len(l.global.sem); n != 1 {
+		t.Errorf("global in-flight = %d, want 1", n)
+	}
+
+	// other classes are limited by the global limiter only
+	search, err := l.acquire(ctx, "/books/_search")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	search()
+	bulk()
+
+	if n := len(l.global.sem) + len(l.classes["bulk"].sem); n != 0 {
+		t.Errorf("in-flight after the release = %d, want 0", n)
+	}
+
+	stats := l.stats()
+	if stats[GlobalLimiter].Requests != 3 || stats["bulk"].Requests != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	l := newLimiters(nil, nil)
+
+	for i := 0; i < 100; i++ {
+		release, err := l.acquire(context.Background(), "/_search")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+
+	if stats := l.stats()[GlobalLimiter]; stats.Requests != 100 || stats.Queued != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestEndpointClass(t *testing.T) {
+	for _, test := range []struct {
+		endpoint string
+		want     string
+	}{
+		{"/_bulk", "bulk"},
+		{"/books/_bulk?refresh=true", "bulk"},
+		{"/books/_search", "search"},
+		{"/_cluster/health", "cluster"},
+		{"/books/_doc/1", "doc"},
+		{"/_opendistro/_security/api/roles", PluginSecurity},
+		{"/_plugins/_ism/policies", PluginIndexManagement},
+		{"/books", ""},
+		{"/", ""},
+	} {
+		if got := endpointClass(test.endpoint); got != test.want {
+			t.Errorf("endpointClass(%s) = %q, want %q", test.endpoint, got, test.want)
+		}
+	}
+}
+
+// assertTokens checks the tokens of a bucket, ignoring the refill since the last request
+func assertTokens(t *testing.T, b *tokenBucket, want float64) {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if math.Abs(b.tokens-want) > 0.1 {
+		t.Errorf("tokens = %.2f, want %.0f", b.tokens, want)
+	}
+}
@@ -8,18 +8,25 @@ package security
 import (
 	"context"
 	"github.com/WhizUs/go-opendistro/common"
-	"net/http"
 )
 
 type ActiongroupService common.Service
 
 type ActiongroupServiceInterface interface {
 	Get(ctx context.Context, name string) (*Actiongroup, error)
-	List(ctx context.Context) (*[]Actiongroup, error)
-	Create(ctx context.Context, name string) error
+	List(ctx context.Context, options *ListOptions) ([]Actiongroup, error)
+	Create(ctx context.Context, name string, actiongroupCreate *ActiongroupCreate) error
+	Replace(ctx context.Context, name string, actiongroupCreate *ActiongroupCreate) error
+	Exists(ctx context.Context, name string) (bool, error)
 	common.Modifyable
 }
 
+type ActiongroupCreate struct {
+	AllowedActions []string `json:"allowed_actions"`
+	Type           string   `json:"type,omitempty"`
+	Description    string   `json:"description,omitempty"`
+}
+
 type Actiongroup struct {
 	Name           string   `json:"name"`
 	Reserved       bool     `json:"reserved"`
@@ -30,79 +37,89 @@ type Actiongroup struct {
 	Static         bool     `json:"static"`
 }
 
+// actiongroups is the resource API of the action groups
+var actiongroups = NewResourceService[Actiongroup, ActiongroupCreate](common.ActiongroupEndpoint, "action group", func(actiongroup *Actiongroup, name string) {
+	actiongroup.Name = name
+})
+
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *ActiongroupService) Get(ctx context.Context, name string) (*Actiongroup, error) {
-	endpoint := common.ActiongroupEndpoint + name
-
-	var actiongroups map[string]*Actiongroup
-
-	err := s.Client.Get(ctx, endpoint, &actiongroups)
-	if err != nil {
-		return nil, err
-	}
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.get")
+	defer done()
 
-	if actiongroups[name] == nil {
-		return nil, nil
-	}
-
-	actiongroups[name].Name = name
-
-	return actiongroups[name], nil
+	return actiongroups.Get(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
-func (s *ActiongroupService) List(ctx context.Context) (*[]Actiongroup, error) {
-	var actiongroups map[string]*Actiongroup
-
-	err := s.Client.Get(ctx, common.ActiongroupEndpoint, &actiongroups)
-	if err != nil {
-		return nil, err
-	}
-
-	var _actiongroups []Actiongroup
-
-	for name, actiongroup := range actiongroups {
-		actiongroup.Name = name
-		_actiongroups = append(_actiongroups, *actiongroup)
-	}
+func (s *ActiongroupService) List(ctx context.Context, options *ListOptions) ([]Actiongroup, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.list")
+	defer done()
 
-	return &_actiongroups, nil
+	return actiongroups.List(ctx, s.Client, options)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *ActiongroupService) Delete(ctx context.Context, name string) error {
-	endpoint := common.ActiongroupEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.delete")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodDelete, nil)
+	return actiongroups.Delete(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
-func (s *ActiongroupService) Create(ctx context.Context, name string) error {
-	endpoint := common.ActiongroupEndpoint + name
+func (s *ActiongroupService) Create(ctx context.Context, name string, actiongroupCreate *ActiongroupCreate) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.create")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPut, nil)
+	return actiongroups.Create(ctx, s.Client, name, actiongroupCreate)
+}
+
+// Replace creates an action group or replaces it if it exists already, it is the same as Create
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
+func (s *ActiongroupService) Replace(ctx context.Context, name string, actiongroupCreate *ActiongroupCreate) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.replace")
+	defer done()
+
+	return actiongroups.Replace(ctx, s.Client, name, actiongroupCreate)
+}
+
+// Exists reports whether an action group with the name exists
+func (s *ActiongroupService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.exists")
+	defer done()
+
+	return actiongroups.Exists(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *ActiongroupService) Update(ctx context.Context, name string, patches *[]common.Patch) error {
-	endpoint := common.ActiongroupEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.update")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPatch, patches)
+	return actiongroups.Patch(ctx, s.Client, name, patches)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *ActiongroupService) UpdateBatch(ctx context.Context, patches *[]common.Patch) error {
-	return s.Update(ctx, "", patches)
+	ctx, done := s.Client.StartOperation(ctx, "security.actiongroups.update_batch")
+	defer done()
+
+	return actiongroups.Patch(ctx, s.Client, "", patches)
+}
+
+func (a *Actiongroup) flags() (bool, bool, bool) {
+	return a.Reserved, a.Hidden, a.Static
 }
@@ -18,6 +18,9 @@ type Health struct {
 }
 
 func (s *HealthService) Get(ctx context.Context) (*Health, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.health.get")
+	defer done()
+
 	endpoint := common.HealthEndpoint
 
 	var health *Health
@@ -0,0 +1,304 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ResourceService implements the API of a named resource of the security plugin, f.e. the internal users.
+// The API returns the resources in a map by name, T is the resource and B the body to create it with.
+// It holds no client and is created once per resource, the methods send the requests with the client
+// passed to them and do not start an operation, the services wrapping them do.
+type ResourceService[T any, B any] struct {
+	endpoint string
+	resource string
+	setName  func(resource *T, name string)
+}
+
+// ListOptions filter the resources of a list, the filters are applied by the client as the API does not
+// support them. The backend role filter is only supported by users and role mappings, role mappings are
+// never static.
+type ListOptions struct {
+	// Prefix of the names of the resources
+	Prefix string
+
+	// Pattern matches the names of the resources with a glob pattern (f.e. "kirk*"), see path.Match
+	Pattern string
+
+	// Reserved, Hidden and Static only include (true) or exclude (false) the resources with the flag
+	Reserved *bool
+	Hidden   *bool
+	Static   *bool
+
+	// BackendRole only includes the users or role mappings with the backend role, List fails if it is set
+	// for other resources
+	BackendRole string
+
+	// Offset and Limit page the filtered resources, a Limit of 0 returns all of them. List fails if
+	// either is negative.
+	Offset int
+	Limit  int
+}
+
+// flagged is implemented by the resources with the reserved, hidden and static flags
+type flagged interface {
+	flags() (reserved bool, hidden bool, static bool)
+}
+
+// withBackendRoles is implemented by the resources assigned to backend roles
+type withBackendRoles interface {
+	backendRoles() []string
+}
+
+// NewResourceService creates the service of the resources at an endpoint (f.e. common.UsersEndpoint).
+// The resource names them in errors (f.e. "user") and setName sets the name of a resource which is only
+// returned as key of the map.
+func NewResourceService[T any, B any](endpoint string, resource string, setName func(resource *T, name string)) *ResourceService[T, B] {
+	return &ResourceService[T, B]{
+		endpoint: endpoint,
+		resource: resource,
+		setName:  setName,
+	}
+}
+
+// Get a single resource by name, a *common.NotFoundError is returned if it does not exist
+func (s *ResourceService[T, B]) Get(ctx context.Context, client common.ClientInterface, name string) (*T, error) {
+	// the resources are decoded after the lookup, the status response of a missing resource is no map of
+	// resources
+	var resources map[string]json.RawMessage
+
+	err := client.Get(ctx, s.endpoint+name, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if resources == nil {
+		return nil, fmt.Errorf("get %s %q: empty response", s.resource, name)
+	}
+
+	// the status response is checked before the lookup, a resource could be named like its fields
+	if sr := statusResponse(resources); sr != nil {
+		if *sr.Status == string(common.Status.NotFound) {
+			return nil, &common.NotFoundError{Resource: s.resource, Name: name}
+		}
+
+		message := *sr.Status
+		if sr.Message != nil {
+			message = *sr.Message
+		}
+
+		return nil, common.NewStatusError(message, nil)
+	}
+
+	raw, ok := resources[name]
+	if !ok {
+		return nil, &common.NotFoundError{Resource: s.resource, Name: name}
+	}
+
+	var resource *T
+
+	err = json.Unmarshal(raw, &resource)
+	if err != nil {
+		return nil, err
+	}
+	if resource == nil {
+		return nil, &common.NotFoundError{Resource: s.resource, Name: name}
+	}
+
+	s.setName(resource, name)
+
+	return resource, nil
+}
+
+// List the resources sorted by name, the options filter and page them
+func (s *ResourceService[T, B]) List(ctx context.Context, client common.ClientInterface, options *ListOptions) ([]T, error) {
+	var zero T
+
+	err := options.validate(s.resource, &zero)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources map[string]*T
+
+	err = client.Get(ctx, s.endpoint, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resources))
+	for name, resource := range resources {
+		if resource != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	list := make([]T, 0, len(names))
+	for _, name := range names {
+		resource := resources[name]
+		s.setName(resource, name)
+
+		if options.match(name, resource) {
+			list = append(list, *resource)
+		}
+	}
+
+	return page(list, options), nil
+}
+
+// Create a resource or replace it if it exists already, the API does not distinguish both. Callers which
+// must not replace a resource have to check it with Exists, which is not atomic with the creation.
+func (s *ResourceService[T, B]) Create(ctx context.Context, client common.ClientInterface, name string, body *B) error {
+	return s.Replace(ctx, client, name, body)
+}
+
+// Replace creates a resource or replaces it if it exists already, it is the same as Create
+func (s *ResourceService[T, B]) Replace(ctx context.Context, client common.ClientInterface, name string, body *B) error {
+	// a missing body is not sent as null
+	var reqBytes interface{}
+	if body != nil {
+		reqBytes = body
+	}
+
+	return client.Modify(ctx, s.endpoint+name, http.MethodPut, reqBytes)
+}
+
+// Patch a resource by name, with an empty name the patches can modify multiple resources at once
+func (s *ResourceService[T, B]) Patch(ctx context.Context, client common.ClientInterface, name string, patches *[]common.Patch) error {
+	return client.Modify(ctx, s.endpoint+name, http.MethodPatch, patches)
+}
+
+// Delete a resource by name
+func (s *ResourceService[T, B]) Delete(ctx context.Context, client common.ClientInterface, name string) error {
+	return client.Modify(ctx, s.endpoint+name, http.MethodDelete, nil)
+}
+
+// Exists reports whether a resource with the name exists
+func (s *ResourceService[T, B]) Exists(ctx context.Context, client common.ClientInterface, name string) (bool, error) {
+	_, err := s.Get(ctx, client, name)
+	if errors.Is(err, common.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// statusResponse returns the status response of the security plugin if the resources are one, its status
+// is a string while resources are objects
+func statusResponse(resources map[string]json.RawMessage) *common.StatusResponse {
+	status, ok := resources["status"]
+	if !ok || len(status) == 0 || status[0] != '"' {
+		return nil
+	}
+
+	b, err := json.Marshal(resources)
+	if err != nil {
+		return nil
+	}
+
+	var sr *common.StatusResponse
+
+	if err := json.Unmarshal(b, &sr); err != nil || sr == nil || sr.Status == nil {
+		return nil
+	}
+
+	return sr
+}
+
+// validate checks the pattern, the paging and that the filters are supported by the resource
+func (o *ListOptions) validate(name string, resource interface{}) error {
+	if o == nil {
+		return nil
+	}
+
+	if o.Offset < 0 || o.Limit < 0 {
+		return fmt.Errorf("offset %d and limit %d must not be negative", o.Offset, o.Limit)
+	}
+
+	if o.Pattern != "" {
+		if _, err := path.Match(o.Pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", o.Pattern, err)
+		}
+	}
+
+	if _, ok := resource.(withBackendRoles); o.BackendRole != "" && !ok {
+		return fmt.Errorf("%s has no backend roles to filter by", name)
+	}
+
+	return nil
+}
+
+func (o *ListOptions) match(name string, resource interface{}) bool {
+	if o == nil {
+		return true
+	}
+
+	if !strings.HasPrefix(name, o.Prefix) {
+		return false
+	}
+	// the pattern is validated already
+	if o.Pattern != "" {
+		if ok, _ := path.Match(o.Pattern, name); !ok {
+			return false
+		}
+	}
+
+	if f, ok := resource.(flagged); ok {
+		reserved, hidden, static := f.flags()
+
+		if !matchFlag(o.Reserved, reserved) || !matchFlag(o.Hidden, hidden) || !matchFlag(o.Static, static) {
+			return false
+		}
+	}
+
+	if r, ok := resource.(withBackendRoles); ok && o.BackendRole != "" {
+		for _, backendRole := range r.backendRoles() {
+			if backendRole == o.BackendRole {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// page returns the page of a list selected by the offset and limit of the options
+func page[T any](list []T, options *ListOptions) []T {
+	if options == nil {
+		return list
+	}
+
+	if options.Offset > 0 {
+		if options.Offset >= len(list) {
+			return list[:0]
+		}
+		list = list[options.Offset:]
+	}
+	if options.Limit > 0 && options.Limit < len(list) {
+		list = list[:options.Limit]
+	}
+
+	return list
+}
+
+func matchFlag(filter *bool, flag bool) bool {
+	return filter == nil || *filter == flag
+}
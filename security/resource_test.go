@@ -0,0 +1,400 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package security_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro"
+	"github.com/WhizUs/go-opendistro/common"
+	"github.com/WhizUs/go-opendistro/security"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const apiPrefix = "/_opendistro/_security/api/"
+
+// fakeSecurity emulates the REST API of the security plugin, the resources are kept by their type (f.e.
+// "internalusers") and name. Patches are recorded but not applied.
+type fakeSecurity struct {
+	mu        sync.Mutex
+	resources map[string]map[string]json.RawMessage
+	requests  []string
+}
+
+func newFakeSecurity() *fakeSecurity {
+	return &fakeSecurity{resources: map[string]map[string]json.RawMessage{}}
+}
+
+func (f *fakeSecurity) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/":
+		fmt.Fprint(w, `{"name":"node-1","version":{"number":"7.10.2"}}`)
+		return
+	case "/_cat/plugins":
+		fmt.Fprint(w, `[{"name":"node-1","component":"opendistro_security","version":"1.13.1.0"}]`)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	f.requests = append(f.requests, strings.TrimSpace(r.Method+" "+strings.TrimPrefix(r.URL.Path, apiPrefix)+" "+string(body)))
+
+	kind, name := strings.TrimPrefix(r.URL.Path, apiPrefix), ""
+	if i := strings.Index(kind, "/"); i >= 0 {
+		kind, name = kind[:i], kind[i+1:]
+	}
+
+	resources := f.resources[kind]
+	if resources == nil {
+		resources = map[string]json.RawMessage{}
+		f.resources[kind] = resources
+	}
+
+	_, exists := resources[name]
+
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		_ = json.NewEncoder(w).Encode(resources)
+
+	case r.Method == http.MethodGet && exists:
+		_ = json.NewEncoder(w).Encode(map[string]json.RawMessage{name: resources[name]})
+
+	case r.Method == http.MethodPut:
+		resources[name] = body
+		if exists {
+			fmt.Fprintf(w, `{"status":"OK","message":"'%s' updated."}`, name)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"status":"CREATED","message":"'%s' created."}`, name)
+
+	case r.Method == http.MethodPatch && (exists || name == ""):
+		fmt.Fprint(w, `{"status":"OK","message":"Resource updated."}`)
+
+	case r.Method == http.MethodDelete && exists:
+		delete(resources, name)
+		fmt.Fprintf(w, `{"status":"OK","message":"'%s' deleted."}`, name)
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"status":"NOT_FOUND","message":"Resource '%s' not found."}`, name)
+	}
+}
+
+// add stores a resource as the API returns it
+func (f *fakeSecurity) add(kind string, name string, resource string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.resources[kind] == nil {
+		f.resources[kind] = map[string]json.RawMessage{}
+	}
+	f.resources[kind][name] = json.RawMessage(resource)
+}
+
+// takeRequests returns the requests of the resource APIs since the last call
+func (f *fakeSecurity) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	requests := f.requests
+	f.requests = nil
+
+	return requests
+}
+
+func newClient(t *testing.T, handler http.Handler) *opendistro.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := opendistro.NewClient(&opendistro.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.Logger = nil
+
+	return client
+}
+
+func TestCreateIsSinglePut(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	client := newClient(t, fake)
+
+	err := client.Security.Users.Create(ctx, "kirk", &security.UserCreate{Password: "secret", BackendRoles: []string{"captains"}})
+	if err != nil {
+		t.Fatalf("create: %s", err)
+	}
+
+	// creating an existing resource replaces it
+	err = client.Security.Tenants.Create(ctx, "enterprise", &security.TenantCreate{Description: "crew"})
+	if err != nil {
+		t.Fatalf("create tenant: %s", err)
+	}
+	err = client.Security.Tenants.Create(ctx, "enterprise", &security.TenantCreate{Description: "bridge"})
+	if err != nil {
+		t.Fatalf("create existing tenant: %s", err)
+	}
+
+	want := []string{
+		`PUT internalusers/kirk {"password":"secret","backend_roles":["captains"],"opendistro_security_roles":null}`,
+		`PUT tenants/enterprise {"description":"crew"}`,
+		`PUT tenants/enterprise {"description":"bridge"}`,
+	}
+	if requests := fake.takeRequests(); !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	fake.add("roles", "captain", `{"cluster_permissions":["cluster_all"]}`)
+	client := newClient(t, fake)
+
+	err := client.Security.Roles.Replace(ctx, "captain", &security.RolePermissions{ClusterPermissions: []string{"cluster_monitor"}})
+	if err != nil {
+		t.Fatalf("replace: %s", err)
+	}
+
+	role, err := client.Security.Roles.Get(ctx, "captain")
+	if err != nil {
+		t.Fatalf("get: %s", err)
+	}
+	if role.Name != "captain" || !reflect.DeepEqual(role.ClusterPermissions, []string{"cluster_monitor"}) {
+		t.Errorf("role = %+v", role)
+	}
+
+	if requests := fake.takeRequests(); len(requests) != 2 || !strings.HasPrefix(requests[0], "PUT roles/captain ") {
+		t.Errorf("requests = %q, want a single PUT and the GET", requests)
+	}
+}
+
+func TestExists(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	fake.add("actiongroups", "read", `{"allowed_actions":["indices:data/read*"]}`)
+	client := newClient(t, fake)
+
+	exists, err := client.Security.Actiongroups.Exists(ctx, "read")
+	if err != nil || !exists {
+		t.Errorf("exists = %t, %v, want true", exists, err)
+	}
+
+	exists, err = client.Security.Actiongroups.Exists(ctx, "write")
+	if err != nil || exists {
+		t.Errorf("exists of a missing action group = %t, %v, want false", exists, err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	fake.add("rolesmapping", "captain", `{"users":["kirk"]}`)
+	client := newClient(t, fake)
+
+	patches := &[]common.Patch{{Op: "add", Path: "/users", Value: []string{"kirk", "picard"}}}
+
+	if err := client.Security.Rolesmapping.Update(ctx, "captain", patches); err != nil {
+		t.Fatalf("update: %s", err)
+	}
+	if err := client.Security.Rolesmapping.UpdateBatch(ctx, patches); err != nil {
+		t.Fatalf("update batch: %s", err)
+	}
+
+	err := client.Security.Rolesmapping.Update(ctx, "missing", patches)
+	if err == nil {
+		t.Error("update of a missing role mapping returned no error")
+	}
+
+	want := []string{
+		`PATCH rolesmapping/captain [{"op":"add","path":"/users","value":["kirk","picard"]}]`,
+		`PATCH rolesmapping/ [{"op":"add","path":"/users","value":["kirk","picard"]}]`,
+		`PATCH rolesmapping/missing [{"op":"add","path":"/users","value":["kirk","picard"]}]`,
+	}
+	if requests := fake.takeRequests(); !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+}
+
+func TestListSorted(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	for _, name := range []string{"uhura", "kirk", "spock"} {
+		fake.add("internalusers", name, `{"backend_roles":["crew"]}`)
+	}
+	client := newClient(t, fake)
+
+	users, err := client.Security.Users.List(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"kirk", "spock", "uhura"}) {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestListOptions(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	fake.add("internalusers", "admin", `{"reserved":true,"backend_roles":["admin"]}`)
+	fake.add("internalusers", "kibanaserver", `{"reserved":true,"hidden":true}`)
+	fake.add("internalusers", "kirk", `{"backend_roles":["captains","crew"]}`)
+	fake.add("internalusers", "kirk-mirror", `{"static":true,"backend_roles":["captains"]}`)
+	fake.add("internalusers", "spock", `{"backend_roles":["crew"]}`)
+	fake.add("internalusers", "uhura", `{"backend_roles":["crew"]}`)
+	client := newClient(t, fake)
+
+	yes, no := true, false
+
+	for _, test := range []struct {
+		name    string
+		options *security.ListOptions
+		want    string
+	}{
+		{"nil", nil, "[admin kibanaserver kirk kirk-mirror spock uhura]"},
+		{"prefix", &security.ListOptions{Prefix: "kirk"}, "[kirk kirk-mirror]"},
+		{"pattern", &security.ListOptions{Pattern: "*r*"}, "[kibanaserver kirk kirk-mirror uhura]"},
+		{"pattern and prefix", &security.ListOptions{Prefix: "k", Pattern: "*-*"}, "[kirk-mirror]"},
+		{"character class", &security.ListOptions{Pattern: "[su]*"}, "[spock uhura]"},
+		{"reserved", &security.ListOptions{Reserved: &yes}, "[admin kibanaserver]"},
+		{"not reserved", &security.ListOptions{Reserved: &no}, "[kirk kirk-mirror spock uhura]"},
+		{"hidden", &security.ListOptions{Hidden: &yes}, "[kibanaserver]"},
+		{"reserved not hidden", &security.ListOptions{Reserved: &yes, Hidden: &no}, "[admin]"},
+		{"static", &security.ListOptions{Static: &yes}, "[kirk-mirror]"},
+		{"not static", &security.ListOptions{Static: &no, Reserved: &no}, "[kirk spock uhura]"},
+		{"backend role", &security.ListOptions{BackendRole: "captains"}, "[kirk kirk-mirror]"},
+		{"backend role and flag", &security.ListOptions{BackendRole: "captains", Static: &no}, "[kirk]"},
+		{"unknown backend role", &security.ListOptions{BackendRole: "klingons"}, "[]"},
+		{"offset", &security.ListOptions{Offset: 4}, "[spock uhura]"},
+		{"limit", &security.ListOptions{Limit: 2}, "[admin kibanaserver]"},
+		{"offset and limit", &security.ListOptions{Offset: 2, Limit: 2}, "[kirk kirk-mirror]"},
+		{"limit past the end", &security.ListOptions{Offset: 5, Limit: 10}, "[uhura]"},
+		{"offset at the end", &security.ListOptions{Offset: 6}, "[]"},
+		{"offset past the end", &security.ListOptions{Offset: 100, Limit: 1}, "[]"},
+		{"zero limit", &security.ListOptions{Offset: 1, Limit: 0}, "[kibanaserver kirk kirk-mirror spock uhura]"},
+		{"page of the filtered list", &security.ListOptions{BackendRole: "crew", Offset: 1, Limit: 1}, "[spock]"},
+	} {
+		users, err := client.Security.Users.List(ctx, test.options)
+		if err != nil {
+			t.Errorf("%s: %s", test.name, err)
+			continue
+		}
+
+		names := []string{}
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+
+		if got := fmt.Sprint(names); got != test.want {
+			t.Errorf("%s: names = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestListOptionsErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := newFakeSecurity()
+	fake.add("internalusers", "kirk", `{}`)
+	fake.add("roles", "captain", `{}`)
+	client := newClient(t, fake)
+
+	for name, options := range map[string]*security.ListOptions{
+		"invalid pattern": {Pattern: "[kirk"},
+		"negative offset": {Offset: -1},
+		"negative limit":  {Limit: -1},
+	} {
+		if users, err := client.Security.Users.List(ctx, options); err == nil {
+			t.Errorf("%s: list = %v, want an error", name, users)
+		}
+	}
+
+	// the backend role filter is rejected for resources without backend roles
+	if roles, err := client.Security.Roles.List(ctx, &security.ListOptions{BackendRole: "crew"}); err == nil {
+		t.Errorf("list of roles by backend role = %v, want an error", roles)
+	}
+	if _, err := client.Security.Rolesmapping.List(ctx, &security.ListOptions{BackendRole: "crew"}); err != nil {
+		t.Errorf("list of role mappings by backend role: %s", err)
+	}
+
+	// invalid options are rejected before the request
+	if requests := fake.takeRequests(); len(requests) != 1 {
+		t.Errorf("requests = %q, want the role mappings only", requests)
+	}
+}
+
+func TestGetNotFound(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name     string
+		status   int
+		body     string
+		notFound bool
+	}{
+		{"status response", http.StatusNotFound, `{"status":"NOT_FOUND","message":"Resource 'kirk' not found."}`, true},
+		{"status without message", http.StatusOK, `{"status":"NOT_FOUND"}`, true},
+		{"missing key", http.StatusOK, `{"spock":{"backend_roles":["crew"]}}`, true},
+		{"empty map", http.StatusOK, `{}`, true},
+		{"null resource", http.StatusOK, `{"kirk":null}`, true},
+		{"other status", http.StatusOK, `{"status":"FORBIDDEN","message":"No permission"}`, false},
+		{"empty response", http.StatusOK, `null`, false},
+	} {
+		client := newClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(test.status)
+			fmt.Fprint(w, test.body)
+		}))
+
+		user, err := client.Security.Users.Get(ctx, "kirk")
+		if user != nil || err == nil {
+			t.Errorf("%s: get = %+v, %v, want an error", test.name, user, err)
+			continue
+		}
+
+		var notFound *common.NotFoundError
+		if errors.As(err, &notFound) != test.notFound || errors.Is(err, common.ErrNotFound) != test.notFound {
+			t.Errorf("%s: get = %v, want a not found error %t", test.name, err, test.notFound)
+		}
+		if test.notFound && (notFound.Resource != "user" || notFound.Name != "kirk") {
+			t.Errorf("%s: not found error = %+v", test.name, notFound)
+		}
+
+		exists, err := client.Security.Users.Exists(ctx, "kirk")
+		if exists || (err == nil) != test.notFound {
+			t.Errorf("%s: exists = %t, %v", test.name, exists, err)
+		}
+	}
+}
+
+func TestGetResourceNamedStatus(t *testing.T) {
+	fake := newFakeSecurity()
+	fake.add("tenants", "status", `{"description":"status board"}`)
+	client := newClient(t, fake)
+
+	tenant, err := client.Security.Tenants.Get(context.Background(), "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenant.Name != "status" || tenant.Description != "status board" {
+		t.Errorf("tenant = %+v", tenant)
+	}
+}
@@ -8,15 +8,16 @@ package security
 import (
 	"context"
 	"github.com/WhizUs/go-opendistro/common"
-	"net/http"
 )
 
 type RoleService common.Service
 
 type RoleServiceInterface interface {
 	Get(ctx context.Context, name string) (*Role, error)
-	List(ctx context.Context) ([]*Role, error)
+	List(ctx context.Context, options *ListOptions) ([]Role, error)
 	Create(ctx context.Context, name string, rolePermissions *RolePermissions) error
+	Replace(ctx context.Context, name string, rolePermissions *RolePermissions) error
+	Exists(ctx context.Context, name string) (bool, error)
 	common.Modifyable
 }
 
@@ -48,79 +49,89 @@ type TenantPermissions struct {
 	AllowedActions []string `json:"allowed_actions,omitempty"`
 }
 
-// Get a single role by name
+// roles is the resource API of the roles
+var roles = NewResourceService[Role, RolePermissions](common.RolesEndpoint, "role", func(role *Role, name string) {
+	role.Name = name
+})
+
+// Get a single role by name, a *common.NotFoundError is returned if it does not exist
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-role
 func (s *RoleService) Get(ctx context.Context, name string) (*Role, error) {
-	endpoint := common.RolesEndpoint + name
-
-	var roles map[string]*Role
-
-	err := s.Client.Get(ctx, endpoint, &roles)
-	if err != nil {
-		return nil, err
-	}
-
-	if roles[name] == nil {
-		return nil, nil
-	}
-
-	roles[name].Name = name
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.get")
+	defer done()
 
-	return roles[name], nil
+	return roles.Get(ctx, s.Client, name)
 }
 
-// List all roles
+// List all roles sorted by name, the options filter and page them
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-roles
-func (s *RoleService) List(ctx context.Context) ([]*Role, error) {
-	var roles map[string]*Role
+func (s *RoleService) List(ctx context.Context, options *ListOptions) ([]Role, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.list")
+	defer done()
 
-	err := s.Client.Get(ctx, common.RolesEndpoint, &roles)
-	if err != nil {
-		return nil, err
-	}
-
-	var _roles []*Role
-
-	for name, role := range roles {
-		role.Name = name
-		_roles = append(_roles, role)
-	}
-
-	return _roles, nil
+	return roles.List(ctx, s.Client, options)
 }
 
 // Delete a role by name
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#delete-role
 func (s *RoleService) Delete(ctx context.Context, name string) error {
-	endpoint := common.RolesEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.delete")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodDelete, nil)
+	return roles.Delete(ctx, s.Client, name)
 }
 
-// Create a role with permissions
+// Create or replace a role with permissions
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-role
 func (s *RoleService) Create(ctx context.Context, name string, rolePermissions *RolePermissions) error {
-	endpoint := common.RolesEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.create")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPut, rolePermissions)
+	return roles.Create(ctx, s.Client, name, rolePermissions)
+}
+
+// Replace creates a role or replaces it if it exists already, it is the same as Create
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-role
+func (s *RoleService) Replace(ctx context.Context, name string, rolePermissions *RolePermissions) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.replace")
+	defer done()
+
+	return roles.Replace(ctx, s.Client, name, rolePermissions)
+}
+
+// Exists reports whether a role with the name exists
+func (s *RoleService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.exists")
+	defer done()
+
+	return roles.Exists(ctx, s.Client, name)
 }
 
 // Update a role
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-role
 func (s *RoleService) Update(ctx context.Context, name string, patches *[]common.Patch) error {
-	endpoint := common.RolesEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.update")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPatch, patches)
+	return roles.Patch(ctx, s.Client, name, patches)
 }
 
 // Update multiple roles at once
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-roles
 func (s *RoleService) UpdateBatch(ctx context.Context, patches *[]common.Patch) error {
-	return s.Update(ctx, "", patches)
+	ctx, done := s.Client.StartOperation(ctx, "security.roles.update_batch")
+	defer done()
+
+	return roles.Patch(ctx, s.Client, "", patches)
+}
+
+func (r *Role) flags() (bool, bool, bool) {
+	return r.IsReserved, r.IsHidden, r.IsStatic
 }
@@ -8,15 +8,16 @@ package security
 import (
 	"context"
 	"github.com/WhizUs/go-opendistro/common"
-	"net/http"
 )
 
 type RolesmappingService common.Service
 
 type RolesmappingServiceInterface interface {
 	Get(ctx context.Context, name string) (*RoleMapping, error)
-	List(ctx context.Context) (*[]RoleMapping, error)
+	List(ctx context.Context, options *ListOptions) ([]RoleMapping, error)
 	Create(ctx context.Context, name string, roleMappingRelations *RoleMappingRelations) error
+	Replace(ctx context.Context, name string, roleMappingRelations *RoleMappingRelations) error
+	Exists(ctx context.Context, name string) (bool, error)
 	common.Modifyable
 }
 
@@ -34,79 +35,93 @@ type RoleMappingRelations struct {
 	Users        []string `json:"users,omitempty"`
 }
 
+// roleMappings is the resource API of the role mappings
+var roleMappings = NewResourceService[RoleMapping, RoleMappingRelations](common.RolesMappingEndpoint, "role mapping", func(roleMapping *RoleMapping, name string) {
+	roleMapping.Name = name
+})
+
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *RolesmappingService) Get(ctx context.Context, name string) (*RoleMapping, error) {
-	endpoint := common.RolesMappingEndpoint + name
-
-	var rolemappings map[string]*RoleMapping
-
-	err := s.Client.Get(ctx, endpoint, &rolemappings)
-	if err != nil {
-		return nil, err
-	}
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.get")
+	defer done()
 
-	if rolemappings[name] == nil {
-		return nil, nil
-	}
-
-	rolemappings[name].Name = name
-
-	return rolemappings[name], nil
+	return roleMappings.Get(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
-func (s *RolesmappingService) List(ctx context.Context) (*[]RoleMapping, error) {
-	var rolemappings map[string]*RoleMapping
-
-	err := s.Client.Get(ctx, common.RolesMappingEndpoint, &rolemappings)
-	if err != nil {
-		return nil, err
-	}
+func (s *RolesmappingService) List(ctx context.Context, options *ListOptions) ([]RoleMapping, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.list")
+	defer done()
 
-	var _rolemappings []RoleMapping
-
-	for name, rolemapping := range rolemappings {
-		rolemapping.Name = name
-		_rolemappings = append(_rolemappings, *rolemapping)
-	}
-
-	return &_rolemappings, nil
+	return roleMappings.List(ctx, s.Client, options)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *RolesmappingService) Delete(ctx context.Context, name string) error {
-	endpoint := common.RolesMappingEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.delete")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodDelete, nil)
+	return roleMappings.Delete(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *RolesmappingService) Create(ctx context.Context, name string, roleMappingRelations *RoleMappingRelations) error {
-	endpoint := common.RolesMappingEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.create")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPut, roleMappingRelations)
+	return roleMappings.Create(ctx, s.Client, name, roleMappingRelations)
+}
+
+// Replace creates a role mapping or replaces it if it exists already, it is the same as Create
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
+func (s *RolesmappingService) Replace(ctx context.Context, name string, roleMappingRelations *RoleMappingRelations) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.replace")
+	defer done()
+
+	return roleMappings.Replace(ctx, s.Client, name, roleMappingRelations)
+}
+
+// Exists reports whether a role mapping with the name exists
+func (s *RolesmappingService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.exists")
+	defer done()
+
+	return roleMappings.Exists(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *RolesmappingService) Update(ctx context.Context, name string, patches *[]common.Patch) error {
-	endpoint := common.RolesMappingEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.update")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPatch, patches)
+	return roleMappings.Patch(ctx, s.Client, name, patches)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#
 func (s *RolesmappingService) UpdateBatch(ctx context.Context, patches *[]common.Patch) error {
-	return s.Update(ctx, "", patches)
+	ctx, done := s.Client.StartOperation(ctx, "security.rolesmapping.update_batch")
+	defer done()
+
+	return roleMappings.Patch(ctx, s.Client, "", patches)
+}
+
+func (r *RoleMapping) flags() (bool, bool, bool) {
+	return r.IsReserved, r.IsHidden, false
+}
+
+func (r *RoleMapping) backendRoles() []string {
+	return r.BackendRoles
 }
@@ -8,18 +8,23 @@ package security
 import (
 	"context"
 	"github.com/WhizUs/go-opendistro/common"
-	"net/http"
 )
 
 type TenantService common.Service
 
 type TenantServiceInterface interface {
 	Get(ctx context.Context, name string) (*Tenant, error)
-	List(ctx context.Context) (*[]Tenant, error)
-	Create(ctx context.Context, name string) error
+	List(ctx context.Context, options *ListOptions) ([]Tenant, error)
+	Create(ctx context.Context, name string, tenantCreate *TenantCreate) error
+	Replace(ctx context.Context, name string, tenantCreate *TenantCreate) error
+	Exists(ctx context.Context, name string) (bool, error)
 	common.Modifyable
 }
 
+type TenantCreate struct {
+	Description string `json:"description,omitempty"`
+}
+
 type Tenant struct {
 	Name        string `json:"name"`
 	Reserved    bool   `json:"reserved"`
@@ -28,79 +33,89 @@ type Tenant struct {
 	Static      bool   `json:"static"`
 }
 
-// Get a single tenant by name
+// tenants is the resource API of the tenants
+var tenants = NewResourceService[Tenant, TenantCreate](common.TenantEndpoint, "tenant", func(tenant *Tenant, name string) {
+	tenant.Name = name
+})
+
+// Get a single tenant by name, a *common.NotFoundError is returned if it does not exist
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-tenant
 func (s *TenantService) Get(ctx context.Context, name string) (*Tenant, error) {
-	endpoint := common.TenantEndpoint + name
-
-	var tenants map[string]*Tenant
-
-	err := s.Client.Get(ctx, endpoint, &tenants)
-	if err != nil {
-		return nil, err
-	}
-
-	if tenants[name] == nil {
-		return nil, nil
-	}
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.get")
+	defer done()
 
-	tenants[name].Name = name
-
-	return tenants[name], nil
+	return tenants.Get(ctx, s.Client, name)
 }
 
-// List all tenants
+// List all tenants sorted by name, the options filter and page them
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-tenants
-func (s *TenantService) List(ctx context.Context) (*[]Tenant, error) {
-	var tenants map[string]*Tenant
-
-	err := s.Client.Get(ctx, common.TenantEndpoint, &tenants)
-	if err != nil {
-		return nil, err
-	}
-
-	var _tenants []Tenant
+func (s *TenantService) List(ctx context.Context, options *ListOptions) ([]Tenant, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.list")
+	defer done()
 
-	for name, tenant := range tenants {
-		tenant.Name = name
-		_tenants = append(_tenants, *tenant)
-	}
-
-	return &_tenants, nil
+	return tenants.List(ctx, s.Client, options)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#delete-tenant
 func (s *TenantService) Delete(ctx context.Context, name string) error {
-	endpoint := common.TenantEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.delete")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodDelete, nil)
+	return tenants.Delete(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-tenant
-func (s *TenantService) Create(ctx context.Context, name string) error {
-	endpoint := common.TenantEndpoint + name
+func (s *TenantService) Create(ctx context.Context, name string, tenantCreate *TenantCreate) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.create")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPut, nil)
+	return tenants.Create(ctx, s.Client, name, tenantCreate)
+}
+
+// Replace creates a tenant or replaces it if it exists already, it is the same as Create
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-tenant
+func (s *TenantService) Replace(ctx context.Context, name string, tenantCreate *TenantCreate) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.replace")
+	defer done()
+
+	return tenants.Replace(ctx, s.Client, name, tenantCreate)
+}
+
+// Exists reports whether a tenant with the name exists
+func (s *TenantService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.exists")
+	defer done()
+
+	return tenants.Exists(ctx, s.Client, name)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-tenant
 func (s *TenantService) Update(ctx context.Context, name string, patches *[]common.Patch) error {
-	endpoint := common.TenantEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.update")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPatch, patches)
+	return tenants.Patch(ctx, s.Client, name, patches)
 }
 
 //
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-tenants
 func (s *TenantService) UpdateBatch(ctx context.Context, patches *[]common.Patch) error {
-	return s.Update(ctx, "", patches)
+	ctx, done := s.Client.StartOperation(ctx, "security.tenants.update_batch")
+	defer done()
+
+	return tenants.Patch(ctx, s.Client, "", patches)
+}
+
+func (t *Tenant) flags() (bool, bool, bool) {
+	return t.Reserved, t.Hidden, t.Static
 }
@@ -8,15 +8,16 @@ package security
 import (
 	"context"
 	"github.com/WhizUs/go-opendistro/common"
-	"net/http"
 )
 
 type UserService common.Service
 
 type UserServiceInterface interface {
 	Get(ctx context.Context, name string) (*User, error)
-	List(ctx context.Context) (*[]User, error)
+	List(ctx context.Context, options *ListOptions) ([]User, error)
 	Create(ctx context.Context, name string, userCreate *UserCreate) error
+	Replace(ctx context.Context, name string, userCreate *UserCreate) error
+	Exists(ctx context.Context, name string) (bool, error)
 	ChangePassword(ctx context.Context, name string, newPassword string) error
 	common.Modifyable
 }
@@ -39,85 +40,94 @@ type User struct {
 	Static       bool              `json:"static"`
 }
 
-// Get a single user by name
+// users is the resource API of the internal users
+var users = NewResourceService[User, UserCreate](common.UsersEndpoint, "user", func(user *User, name string) {
+	user.Name = name
+})
+
+// Get a single user by name, a *common.NotFoundError is returned if it does not exist
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-user
 func (s *UserService) Get(ctx context.Context, name string) (*User, error) {
-	endpoint := common.UsersEndpoint + name
-
-	var users map[string]*User
-
-	err := s.Client.Get(ctx, endpoint, &users)
-	if err != nil {
-		return nil, err
-	}
-
-	if users[name] == nil {
-		return nil, nil
-	}
-
-	users[name].Name = name
+	ctx, done := s.Client.StartOperation(ctx, "security.users.get")
+	defer done()
 
-	return users[name], nil
+	return users.Get(ctx, s.Client, name)
 }
 
-// List all users
+// List all users sorted by name, the options filter and page them
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#get-users
-func (s *UserService) List(ctx context.Context) (*[]User, error) {
-	var users map[string]*User
+func (s *UserService) List(ctx context.Context, options *ListOptions) ([]User, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.users.list")
+	defer done()
 
-	err := s.Client.Get(ctx, common.UsersEndpoint, &users)
-	if err != nil {
-		return nil, err
-	}
-
-	var _users []User
-
-	for name, user := range users {
-		user.Name = name
-		_users = append(_users, *user)
-	}
-
-	return &_users, nil
+	return users.List(ctx, s.Client, options)
 }
 
 // Delete a user by name
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#delete-user
 func (s *UserService) Delete(ctx context.Context, name string) error {
-	endpoint := common.UsersEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.users.delete")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodDelete, nil)
+	return users.Delete(ctx, s.Client, name)
 }
 
 // Create or replace the specified user. Password can be submitted in plain text (password) or hashed (hash). If a plain text password is submitted, the Security Plugin will do the hashing.
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-user
 func (s *UserService) Create(ctx context.Context, name string, userCreate *UserCreate) error {
-	endpoint := common.UsersEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.users.create")
+	defer done()
+
+	return users.Create(ctx, s.Client, name, userCreate)
+}
+
+// Replace creates a user or replaces it if it exists already, it is the same as Create
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#create-user
+func (s *UserService) Replace(ctx context.Context, name string, userCreate *UserCreate) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.users.replace")
+	defer done()
+
+	return users.Replace(ctx, s.Client, name, userCreate)
+}
+
+// Exists reports whether a user with the name exists
+func (s *UserService) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, done := s.Client.StartOperation(ctx, "security.users.exists")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPut, userCreate)
+	return users.Exists(ctx, s.Client, name)
 }
 
 // Update a user by name and providing an update patch
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-user
 func (s *UserService) Update(ctx context.Context, name string, patches *[]common.Patch) error {
-	endpoint := common.UsersEndpoint + name
+	ctx, done := s.Client.StartOperation(ctx, "security.users.update")
+	defer done()
 
-	return s.Client.Modify(ctx, endpoint, http.MethodPatch, patches)
+	return users.Patch(ctx, s.Client, name, patches)
 }
 
 // Update multiple users at once
 //
 // see: https://opendistro.github.io/for-elasticsearch-docs/docs/security-access-control/api/#patch-users
 func (s *UserService) UpdateBatch(ctx context.Context, patches *[]common.Patch) error {
-	return s.Update(ctx, "", patches)
+	ctx, done := s.Client.StartOperation(ctx, "security.users.update_batch")
+	defer done()
+
+	return users.Patch(ctx, s.Client, "", patches)
 }
 
 // ChangePassword applies the new password to the user provided by name
 func (s *UserService) ChangePassword(ctx context.Context, name string, newPassword string) error {
+	ctx, done := s.Client.StartOperation(ctx, "security.users.change_password")
+	defer done()
+
 	patch := &[]common.Patch{
 		{
 			Op:   "add",
@@ -130,3 +140,11 @@ func (s *UserService) ChangePassword(ctx context.Context, name string, newPasswo
 
 	return s.UpdateBatch(ctx, patch)
 }
+
+func (u *User) flags() (bool, bool, bool) {
+	return u.Reserved, u.Hidden, u.Static
+}
+
+func (u *User) backendRoles() []string {
+	return u.BackendRoles
+}
@@ -0,0 +1,179 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strings"
+)
+
+// RepositoryTypeFs is the type of a repository on a shared file system
+const RepositoryTypeFs = "fs"
+
+type RepositoryService common.Service
+
+type RepositoryServiceInterface interface {
+	Get(ctx context.Context, name string) (*Repository, error)
+	List(ctx context.Context) (map[string]*Repository, error)
+	Create(ctx context.Context, name string, repository *Repository, verify bool) error
+	Verify(ctx context.Context, name string) (map[string]*VerifiedNode, error)
+	Delete(ctx context.Context, names ...string) error
+}
+
+// Repository of snapshots. Settings depend on the type, f.e. {"location":"/mnt/backups"} for "fs" (see
+// NewFsRepository) or {"bucket":"backups"} for "s3".
+type Repository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// FsSettings of a repository on a shared file system, Location has to be listed in path.repo of all
+// nodes. ChunkSize splits large files (f.e. "1g"), they are not split if it is empty.
+type FsSettings struct {
+	Location               string
+	Compress               bool
+	ChunkSize              string
+	MaxSnapshotBytesPerSec string
+	MaxRestoreBytesPerSec  string
+	Readonly               bool
+}
+
+type VerifiedNode struct {
+	Name string `json:"name"`
+}
+
+type verifyResponse struct {
+	Nodes map[string]*VerifiedNode `json:"nodes"`
+}
+
+// NewFsRepository returns a repository on a shared file system
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/snapshots-register-repository.html#snapshots-filesystem-repository
+func NewFsRepository(settings *FsSettings) *Repository {
+	s := map[string]interface{}{
+		"location": settings.Location,
+		"compress": settings.Compress,
+	}
+
+	if settings.ChunkSize != "" {
+		s["chunk_size"] = settings.ChunkSize
+	}
+	if settings.MaxSnapshotBytesPerSec != "" {
+		s["max_snapshot_bytes_per_sec"] = settings.MaxSnapshotBytesPerSec
+	}
+	if settings.MaxRestoreBytesPerSec != "" {
+		s["max_restore_bytes_per_sec"] = settings.MaxRestoreBytesPerSec
+	}
+	if settings.Readonly {
+		s["readonly"] = true
+	}
+
+	return &Repository{
+		Type:     RepositoryTypeFs,
+		Settings: s,
+	}
+}
+
+// Get a single repository by name, a *common.NotFoundError is returned if it does not exist
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/get-snapshot-repo-api.html
+func (s *RepositoryService) Get(ctx context.Context, name string) (*Repository, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.repositories.get")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + name
+
+	var repositories map[string]*Repository
+
+	// a missing repository is reported as repository_missing_exception with status 404
+	err := s.Client.Get(ctx, endpoint, &repositories)
+
+	var re *common.ResponseError
+	if errors.As(err, &re) && re.StatusCode == http.StatusNotFound {
+		return nil, &common.NotFoundError{Resource: "repository", Name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if repositories[name] == nil {
+		return nil, &common.NotFoundError{Resource: "repository", Name: name}
+	}
+
+	return repositories[name], nil
+}
+
+// List all repositories by name
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/get-snapshot-repo-api.html
+func (s *RepositoryService) List(ctx context.Context) (map[string]*Repository, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.repositories.list")
+	defer done()
+
+	var repositories map[string]*Repository
+
+	err := s.Client.Get(ctx, common.SnapshotEndpoint, &repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	return repositories, nil
+}
+
+// Create registers or updates a repository. With verify the repository is checked to be usable by all
+// nodes before it is registered.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/put-snapshot-repo-api.html
+func (s *RepositoryService) Create(ctx context.Context, name string, repository *Repository, verify bool) error {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.repositories.create")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + name
+
+	if !verify {
+		endpoint = endpoint + "?verify=false"
+	}
+
+	_, err := s.Client.Do(ctx, repository, endpoint, http.MethodPut)
+
+	return err
+}
+
+// Verify checks that a repository is usable and returns the nodes by id which verified it
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/verify-snapshot-repo-api.html
+func (s *RepositoryService) Verify(ctx context.Context, name string) (map[string]*VerifiedNode, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.repositories.verify")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + name + "/_verify"
+
+	var response *verifyResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, nil, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Nodes, nil
+}
+
+// Delete unregisters repositories, the snapshots in the repositories are not deleted
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/delete-snapshot-repo-api.html
+func (s *RepositoryService) Delete(ctx context.Context, names ...string) error {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.repositories.delete")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + strings.Join(names, ",")
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
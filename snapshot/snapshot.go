@@ -0,0 +1,302 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is the interval a snapshot is polled with by WaitForCompletion if none is given
+const DefaultPollInterval = 5 * time.Second
+
+const (
+	StateInProgress = "IN_PROGRESS"
+	StateSuccess    = "SUCCESS"
+	StatePartial    = "PARTIAL"
+	StateFailed     = "FAILED"
+)
+
+type SnapshotService common.Service
+
+type SnapshotServiceInterface interface {
+	Create(ctx context.Context, repository string, name string, request *CreateRequest, wait bool) (*Snapshot, error)
+	Get(ctx context.Context, repository string, names ...string) ([]Snapshot, error)
+	List(ctx context.Context, repository string) ([]Snapshot, error)
+	Status(ctx context.Context, repository string, names ...string) ([]Status, error)
+	WaitForCompletion(ctx context.Context, repository string, name string, pollInterval time.Duration) (*Snapshot, error)
+	Delete(ctx context.Context, repository string, name string) error
+	Restore(ctx context.Context, repository string, name string, request *RestoreRequest, wait bool) (*RestoreResult, error)
+}
+
+type CreateRequest struct {
+	// Indices to include, all indices if empty
+	Indices            []string               `json:"indices,omitempty"`
+	IgnoreUnavailable  bool                   `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState *bool                  `json:"include_global_state,omitempty"`
+	Partial            bool                   `json:"partial,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type Snapshot struct {
+	Snapshot           string                 `json:"snapshot"`
+	UUID               string                 `json:"uuid"`
+	VersionID          int                    `json:"version_id"`
+	Version            string                 `json:"version"`
+	Indices            []string               `json:"indices"`
+	IncludeGlobalState bool                   `json:"include_global_state"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	State              string                 `json:"state"`
+	StartTimeInMillis  int64                  `json:"start_time_in_millis"`
+	EndTimeInMillis    int64                  `json:"end_time_in_millis"`
+	DurationInMillis   int64                  `json:"duration_in_millis"`
+	Failures           []ShardFailure         `json:"failures"`
+	Shards             Shards                 `json:"shards"`
+}
+
+type ShardFailure struct {
+	Index   string `json:"index"`
+	ShardID int    `json:"shard_id"`
+	Reason  string `json:"reason"`
+	NodeID  string `json:"node_id"`
+	Status  string `json:"status"`
+}
+
+type Shards struct {
+	Total      int `json:"total"`
+	Failed     int `json:"failed"`
+	Successful int `json:"successful"`
+}
+
+// Status is the detailed status of a snapshot, Indices contains the status per index and shard
+type Status struct {
+	Snapshot           string                     `json:"snapshot"`
+	Repository         string                     `json:"repository"`
+	UUID               string                     `json:"uuid"`
+	State              string                     `json:"state"`
+	IncludeGlobalState bool                       `json:"include_global_state"`
+	ShardsStats        ShardsStats                `json:"shards_stats"`
+	Stats              Stats                      `json:"stats"`
+	Indices            map[string]json.RawMessage `json:"indices"`
+}
+
+type ShardsStats struct {
+	Initializing int `json:"initializing"`
+	Started      int `json:"started"`
+	Finalizing   int `json:"finalizing"`
+	Done         int `json:"done"`
+	Failed       int `json:"failed"`
+	Total        int `json:"total"`
+}
+
+type Stats struct {
+	Incremental       FileStats `json:"incremental"`
+	Processed         FileStats `json:"processed"`
+	Total             FileStats `json:"total"`
+	StartTimeInMillis int64     `json:"start_time_in_millis"`
+	TimeInMillis      int64     `json:"time_in_millis"`
+}
+
+type FileStats struct {
+	FileCount   int   `json:"file_count"`
+	SizeInBytes int64 `json:"size_in_bytes"`
+}
+
+// RestoreRequest of a snapshot. An open index can not be restored, so it has to be closed, deleted or
+// restored with another name by RenamePattern and RenameReplacement (f.e. "(.+)" and "restored_$1").
+type RestoreRequest struct {
+	Indices             []string               `json:"indices,omitempty"`
+	IgnoreUnavailable   bool                   `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState  bool                   `json:"include_global_state,omitempty"`
+	IncludeAliases      *bool                  `json:"include_aliases,omitempty"`
+	Partial             bool                   `json:"partial,omitempty"`
+	RenamePattern       string                 `json:"rename_pattern,omitempty"`
+	RenameReplacement   string                 `json:"rename_replacement,omitempty"`
+	IndexSettings       map[string]interface{} `json:"index_settings,omitempty"`
+	IgnoreIndexSettings []string               `json:"ignore_index_settings,omitempty"`
+}
+
+type RestoreResult struct {
+	Snapshot string   `json:"snapshot"`
+	Indices  []string `json:"indices"`
+	Shards   Shards   `json:"shards"`
+}
+
+type snapshotResponse struct {
+	Snapshot *Snapshot `json:"snapshot"`
+}
+
+type snapshotsResponse struct {
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+type statusResponse struct {
+	Snapshots []Status `json:"snapshots"`
+}
+
+type restoreResponse struct {
+	Snapshot *RestoreResult `json:"snapshot"`
+}
+
+// Create a snapshot. With wait the call blocks until the snapshot is completed and returns it, otherwise
+// nil is returned as soon as the snapshot is started (see WaitForCompletion).
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/create-snapshot-api.html
+func (s *SnapshotService) Create(ctx context.Context, repository string, name string, request *CreateRequest, wait bool) (*Snapshot, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.create")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + repository + "/" + name
+
+	if wait {
+		endpoint = endpoint + "?wait_for_completion=true"
+	}
+
+	var response *snapshotResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPut, request, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Snapshot, nil
+}
+
+// Get snapshots of a repository by name, names can contain wildcards
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/get-snapshot-api.html
+func (s *SnapshotService) Get(ctx context.Context, repository string, names ...string) ([]Snapshot, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.get")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + repository + "/" + strings.Join(names, ",")
+
+	var response *snapshotsResponse
+
+	err := s.Client.Get(ctx, endpoint, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Snapshots, nil
+}
+
+// List all snapshots of a repository
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/get-snapshot-api.html
+func (s *SnapshotService) List(ctx context.Context, repository string) ([]Snapshot, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.list")
+	defer done()
+
+	return s.Get(ctx, repository, "_all")
+}
+
+// Status returns the detailed status of snapshots. Without names the status of the running snapshots of
+// the repository is returned.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/get-snapshot-status-api.html
+func (s *SnapshotService) Status(ctx context.Context, repository string, names ...string) ([]Status, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.status")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + repository
+
+	if len(names) > 0 {
+		endpoint = endpoint + "/" + strings.Join(names, ",")
+	}
+
+	endpoint = endpoint + "/_status"
+
+	var response *statusResponse
+
+	err := s.Client.Get(ctx, endpoint, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Snapshots, nil
+}
+
+// WaitForCompletion polls a snapshot until it is not in progress anymore. The snapshot is returned with
+// an error if it failed. A poll interval <= 0 defaults to DefaultPollInterval.
+func (s *SnapshotService) WaitForCompletion(ctx context.Context, repository string, name string, pollInterval time.Duration) (*Snapshot, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.wait_for_completion")
+	defer done()
+
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	for {
+		snapshots, err := s.Get(ctx, repository, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(snapshots) == 0 {
+			return nil, fmt.Errorf("snapshot %s not found in repository %s", name, repository)
+		}
+
+		snapshot := &snapshots[0]
+
+		switch snapshot.State {
+		case StateInProgress:
+		case StateFailed:
+			return snapshot, fmt.Errorf("snapshot %s failed", name)
+		default:
+			return snapshot, nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Delete a snapshot, a running snapshot is aborted
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/delete-snapshot-api.html
+func (s *SnapshotService) Delete(ctx context.Context, repository string, name string) error {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.delete")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + repository + "/" + name
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodDelete)
+
+	return err
+}
+
+// Restore a snapshot. With wait the call blocks until the restore is completed and returns its result,
+// otherwise nil is returned as soon as the restore is started.
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/restore-snapshot-api.html
+func (s *SnapshotService) Restore(ctx context.Context, repository string, name string, request *RestoreRequest, wait bool) (*RestoreResult, error) {
+	ctx, done := s.Client.StartOperation(ctx, "snapshot.snapshots.restore")
+	defer done()
+
+	endpoint := common.SnapshotEndpoint + repository + "/" + name + "/_restore"
+
+	if wait {
+		endpoint = endpoint + "?wait_for_completion=true"
+	}
+
+	var response *restoreResponse
+
+	err := s.Client.Send(ctx, endpoint, http.MethodPost, request, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Snapshot, nil
+}
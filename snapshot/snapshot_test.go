@@ -0,0 +1,260 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package snapshot_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro"
+	"github.com/WhizUs/go-opendistro/common"
+	"github.com/WhizUs/go-opendistro/snapshot"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCluster emulates the snapshot API of a cluster with an fs repository in memory
+type fakeCluster struct {
+	mu           sync.Mutex
+	repositories map[string]*snapshot.Repository
+	snapshots    map[string]*snapshot.Snapshot
+	indices      []string
+	restores     []*snapshot.RestoreRequest
+	polls        int
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{
+		repositories: map[string]*snapshot.Repository{},
+		snapshots:    map[string]*snapshot.Snapshot{},
+		indices:      []string{"logs"},
+	}
+}
+
+func (f *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/_snapshot"), "/"), "/")
+
+	switch {
+	case r.Method == http.MethodPut && len(parts) == 1:
+		var repository *snapshot.Repository
+		if err := json.NewDecoder(r.Body).Decode(&repository); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.repositories[parts[0]] = repository
+		fmt.Fprint(w, `{"acknowledged":true}`)
+
+	case r.Method == http.MethodGet && len(parts) == 1:
+		repository := f.repositories[parts[0]]
+		if repository == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":{"type":"repository_missing_exception","reason":"[%s] missing"},"status":404}`, parts[0])
+			return
+		}
+		writeJSON(w, map[string]*snapshot.Repository{parts[0]: repository})
+
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "_verify":
+		fmt.Fprint(w, `{"nodes":{"n1":{"name":"node-1"}}}`)
+
+	case r.Method == http.MethodPut && len(parts) == 2:
+		var request *snapshot.CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		state := snapshot.StateInProgress
+		if r.URL.Query().Get("wait_for_completion") == "true" {
+			state = snapshot.StateSuccess
+		}
+
+		s := &snapshot.Snapshot{Snapshot: parts[1], Indices: request.Indices, State: state}
+		f.snapshots[parts[1]] = s
+
+		if state == snapshot.StateSuccess {
+			writeJSON(w, map[string]interface{}{"snapshot": s})
+		} else {
+			fmt.Fprint(w, `{"accepted":true}`)
+		}
+
+	case r.Method == http.MethodGet && len(parts) == 2:
+		s := f.snapshots[parts[1]]
+		if s == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"type":"snapshot_missing_exception","reason":"snapshot is missing"},"status":404}`)
+			return
+		}
+
+		// a running snapshot completes after it was polled once
+		f.polls++
+		response := *s
+		if s.State == snapshot.StateInProgress {
+			s.State = snapshot.StateSuccess
+		}
+
+		writeJSON(w, map[string]interface{}{"snapshots": []snapshot.Snapshot{response}})
+
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "_restore":
+		var request *snapshot.RestoreRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.restores = append(f.restores, request)
+
+		s := f.snapshots[parts[1]]
+		restored := make([]string, 0, len(s.Indices))
+		for _, index := range s.Indices {
+			restored = append(restored, strings.Replace(request.RenameReplacement, "$1", index, 1))
+		}
+		f.indices = append(f.indices, restored...)
+
+		writeJSON(w, map[string]interface{}{"snapshot": &snapshot.RestoreResult{Snapshot: parts[1], Indices: restored}})
+
+	case r.Method == http.MethodDelete && len(parts) == 2:
+		delete(f.snapshots, parts[1])
+		fmt.Fprint(w, `{"acknowledged":true}`)
+
+	case r.Method == http.MethodDelete && len(parts) == 1:
+		delete(f.repositories, parts[0])
+		fmt.Fprint(w, `{"acknowledged":true}`)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newClient(t *testing.T, handler http.Handler) *opendistro.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := opendistro.NewClient(&opendistro.ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client.Logger = nil
+
+	return client
+}
+
+func TestFsRepositoryLifecycle(t *testing.T) {
+	ctx := context.Background()
+	cluster := newFakeCluster()
+	client := newClient(t, cluster)
+
+	repository := snapshot.NewFsRepository(&snapshot.FsSettings{
+		Location:  "/mnt/backups",
+		Compress:  true,
+		ChunkSize: "1g",
+	})
+
+	err := client.Snapshot.Repositories.Create(ctx, "backups", repository, true)
+	if err != nil {
+		t.Fatalf("create repository: %s", err)
+	}
+
+	registered, err := client.Snapshot.Repositories.Get(ctx, "backups")
+	if err != nil {
+		t.Fatalf("get repository: %s", err)
+	}
+	if registered.Type != snapshot.RepositoryTypeFs {
+		t.Errorf("type = %q, want %q", registered.Type, snapshot.RepositoryTypeFs)
+	}
+	for key, want := range map[string]interface{}{"location": "/mnt/backups", "compress": true, "chunk_size": "1g"} {
+		if got := registered.Settings[key]; got != want {
+			t.Errorf("settings[%s] = %v, want %v", key, got, want)
+		}
+	}
+
+	nodes, err := client.Snapshot.Repositories.Verify(ctx, "backups")
+	if err != nil {
+		t.Fatalf("verify repository: %s", err)
+	}
+	if len(nodes) != 1 || nodes["n1"].Name != "node-1" {
+		t.Errorf("verified nodes = %v", nodes)
+	}
+
+	created, err := client.Snapshot.Snapshots.Create(ctx, "backups", "snap-1", &snapshot.CreateRequest{Indices: []string{"logs"}}, true)
+	if err != nil {
+		t.Fatalf("create snapshot: %s", err)
+	}
+	if created.State != snapshot.StateSuccess {
+		t.Errorf("state = %q, want %q", created.State, snapshot.StateSuccess)
+	}
+
+	result, err := client.Snapshot.Snapshots.Restore(ctx, "backups", "snap-1", &snapshot.RestoreRequest{
+		Indices:           []string{"logs"},
+		RenamePattern:     "(.+)",
+		RenameReplacement: "restored_$1",
+	}, true)
+	if err != nil {
+		t.Fatalf("restore snapshot: %s", err)
+	}
+	if len(result.Indices) != 1 || result.Indices[0] != "restored_logs" {
+		t.Errorf("restored indices = %v, want [restored_logs]", result.Indices)
+	}
+	if len(cluster.restores) != 1 || cluster.restores[0].RenamePattern != "(.+)" {
+		t.Errorf("restore requests = %v", cluster.restores)
+	}
+
+	err = client.Snapshot.Snapshots.Delete(ctx, "backups", "snap-1")
+	if err != nil {
+		t.Fatalf("delete snapshot: %s", err)
+	}
+	if len(cluster.snapshots) != 0 {
+		t.Errorf("snapshots = %v, want none", cluster.snapshots)
+	}
+
+	err = client.Snapshot.Repositories.Delete(ctx, "backups")
+	if err != nil {
+		t.Fatalf("delete repository: %s", err)
+	}
+	if len(cluster.repositories) != 0 {
+		t.Errorf("repositories = %v, want none", cluster.repositories)
+	}
+
+	_, err = client.Snapshot.Repositories.Get(ctx, "backups")
+
+	var notFound *common.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Resource != "repository" || notFound.Name != "backups" {
+		t.Errorf("get deleted repository = %v, want a not found error", err)
+	}
+}
+
+func TestWaitForCompletionDefaultsPollInterval(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	cluster := newFakeCluster()
+	client := newClient(t, cluster)
+
+	_, err := client.Snapshot.Snapshots.Create(ctx, "backups", "snap-1", &snapshot.CreateRequest{Indices: []string{"logs"}}, false)
+	if err != nil {
+		t.Fatalf("create snapshot: %s", err)
+	}
+
+	// the first poll returns the running snapshot, a poll interval of 0 must not poll in a tight loop
+	_, err = client.Snapshot.Snapshots.WaitForCompletion(ctx, "backups", "snap-1", 0)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if cluster.polls != 1 {
+		t.Errorf("polls = %d, want 1", cluster.polls)
+	}
+}
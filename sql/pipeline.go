@@ -0,0 +1,394 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_@][A-Za-z0-9_.@]*$`)
+
+// Ident marks a value as a field or index name, so it is quoted as identifier instead of as string literal
+type Ident string
+
+// Pipeline composes a PPL query from commands separated by pipes, f.e.
+//
+//	query, err := sql.Source("accounts").
+//		Where("age > ? and state = ?", 30, "CA").
+//		Stats([]string{"avg(balance)"}, "gender").
+//		Sort("-gender").
+//		Build()
+//
+// Field and index names are quoted as identifiers, values passed for ? placeholders as literals. Expressions
+// (conditions, aggregations, eval expressions) are taken as they are, apart from the placeholders.
+type Pipeline struct {
+	commands []string
+	err      error
+}
+
+// Source starts a pipeline reading from one or more indices (index patterns are allowed)
+func Source(indices ...string) *Pipeline {
+	p := &Pipeline{}
+
+	if len(indices) == 0 {
+		p.err = fmt.Errorf("source requires at least one index")
+		return p
+	}
+
+	names, err := quoteIdentifiers(indices)
+	if err != nil {
+		p.err = err
+		return p
+	}
+
+	p.commands = append(p.commands, "source="+strings.Join(names, ", "))
+
+	return p
+}
+
+// Where filters the results by a condition. The arguments replace the ? placeholders of the condition.
+func (p *Pipeline) Where(condition string, args ...interface{}) *Pipeline {
+	return p.expression("where", condition, args)
+}
+
+// Fields keeps the given fields only
+func (p *Pipeline) Fields(fields ...string) *Pipeline {
+	return p.fields("fields", fields)
+}
+
+// ExcludeFields removes the given fields from the results
+func (p *Pipeline) ExcludeFields(fields ...string) *Pipeline {
+	return p.fields("fields -", fields)
+}
+
+// Eval adds a field calculated by an expression. The arguments replace the ? placeholders of the expression.
+func (p *Pipeline) Eval(field string, expression string, args ...interface{}) *Pipeline {
+	name, err := QuoteIdentifier(field)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.expression("eval "+name+" =", expression, args)
+}
+
+// Stats aggregates the results (f.e. "count()", "avg(age)"), optionally grouped by fields
+func (p *Pipeline) Stats(aggregations []string, by ...string) *Pipeline {
+	if len(aggregations) == 0 {
+		return p.fail(fmt.Errorf("stats requires at least one aggregation"))
+	}
+
+	command := "stats " + strings.Join(aggregations, ", ")
+
+	if len(by) > 0 {
+		names, err := quoteIdentifiers(by)
+		if err != nil {
+			return p.fail(err)
+		}
+		command = command + " by " + strings.Join(names, ", ")
+	}
+
+	return p.add(command)
+}
+
+// Sort sorts the results by fields, fields prefixed with - are sorted descending
+func (p *Pipeline) Sort(fields ...string) *Pipeline {
+	if len(fields) == 0 {
+		return p.fail(fmt.Errorf("sort requires at least one field"))
+	}
+
+	names := make([]string, len(fields))
+
+	for i, field := range fields {
+		prefix := ""
+		if strings.HasPrefix(field, "-") || strings.HasPrefix(field, "+") {
+			prefix = field[:1]
+			field = field[1:]
+		}
+
+		name, err := QuoteIdentifier(field)
+		if err != nil {
+			return p.fail(err)
+		}
+		names[i] = prefix + name
+	}
+
+	return p.add("sort " + strings.Join(names, ", "))
+}
+
+// Dedup removes results with identical values of the given fields, keeping count results per combination
+func (p *Pipeline) Dedup(count int, fields ...string) *Pipeline {
+	if count < 1 {
+		return p.fail(fmt.Errorf("dedup requires a count of at least 1, got %d", count))
+	}
+	if len(fields) == 0 {
+		return p.fail(fmt.Errorf("dedup requires at least one field"))
+	}
+
+	names, err := quoteIdentifiers(fields)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.add(fmt.Sprintf("dedup %d %s", count, strings.Join(names, ", ")))
+}
+
+// Rename renames a field
+func (p *Pipeline) Rename(field string, as string) *Pipeline {
+	names, err := quoteIdentifiers([]string{field, as})
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.add("rename " + names[0] + " as " + names[1])
+}
+
+// Head keeps the first n results
+func (p *Pipeline) Head(n int) *Pipeline {
+	if n < 1 {
+		return p.fail(fmt.Errorf("head requires a count of at least 1, got %d", n))
+	}
+
+	return p.add("head " + strconv.Itoa(n))
+}
+
+// Top finds the n most common values of the fields, optionally grouped by fields
+func (p *Pipeline) Top(n int, fields []string, by ...string) *Pipeline {
+	return p.frequency("top", n, fields, by)
+}
+
+// Rare finds the least common values of the fields, optionally grouped by fields
+func (p *Pipeline) Rare(fields []string, by ...string) *Pipeline {
+	return p.frequency("rare", 0, fields, by)
+}
+
+// Command appends any other command. The arguments replace the ? placeholders of the command.
+func (p *Pipeline) Command(command string, args ...interface{}) *Pipeline {
+	return p.expression("", command, args)
+}
+
+// Build returns the query or the first error which occurred while composing it
+func (p *Pipeline) Build() (string, error) {
+	if p.err != nil {
+		return "", p.err
+	}
+
+	return strings.Join(p.commands, " | "), nil
+}
+
+// String returns the query, or an empty string if composing it failed
+func (p *Pipeline) String() string {
+	query, _ := p.Build()
+	return query
+}
+
+func (p *Pipeline) add(command string) *Pipeline {
+	if p.err == nil {
+		p.commands = append(p.commands, command)
+	}
+
+	return p
+}
+
+func (p *Pipeline) fail(err error) *Pipeline {
+	if p.err == nil {
+		p.err = err
+	}
+
+	return p
+}
+
+func (p *Pipeline) fields(command string, fields []string) *Pipeline {
+	if len(fields) == 0 {
+		return p.fail(fmt.Errorf("%s requires at least one field", command))
+	}
+
+	names, err := quoteIdentifiers(fields)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	return p.add(command + " " + strings.Join(names, ", "))
+}
+
+func (p *Pipeline) frequency(command string, n int, fields []string, by []string) *Pipeline {
+	if len(fields) == 0 {
+		return p.fail(fmt.Errorf("%s requires at least one field", command))
+	}
+
+	names, err := quoteIdentifiers(fields)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	if n > 0 {
+		command = command + " " + strconv.Itoa(n)
+	}
+	command = command + " " + strings.Join(names, ", ")
+
+	if len(by) > 0 {
+		groups, err := quoteIdentifiers(by)
+		if err != nil {
+			return p.fail(err)
+		}
+		command = command + " by " + strings.Join(groups, ", ")
+	}
+
+	return p.add(command)
+}
+
+func (p *Pipeline) expression(command string, expression string, args []interface{}) *Pipeline {
+	bound, err := bind(expression, args)
+	if err != nil {
+		return p.fail(err)
+	}
+
+	if command != "" {
+		bound = command + " " + bound
+	}
+
+	return p.add(bound)
+}
+
+// bind replaces the ? placeholders outside of quoted strings and identifiers by the quoted arguments
+func bind(expression string, args []interface{}) (string, error) {
+	var b strings.Builder
+	var quote rune
+	escaped := false
+	next := 0
+
+	for _, r := range expression {
+		switch {
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			if next >= len(args) {
+				return "", fmt.Errorf("missing argument for placeholder %d in %q", next+1, expression)
+			}
+
+			literal, err := QuoteLiteral(args[next])
+			if err != nil {
+				return "", err
+			}
+			next++
+
+			b.WriteString(literal)
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	if next != len(args) {
+		return "", fmt.Errorf("%d arguments given for %d placeholders in %q", len(args), next, expression)
+	}
+
+	return b.String(), nil
+}
+
+// QuoteIdentifier quotes a field or index name with backticks if required
+func QuoteIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty identifier")
+	}
+
+	if plainIdentifier.MatchString(name) {
+		return name, nil
+	}
+
+	if strings.ContainsAny(name, "`\n\r") {
+		return "", fmt.Errorf("invalid identifier %q", name)
+	}
+
+	return "`" + name + "`", nil
+}
+
+// QuoteLiteral formats a Go value as PPL literal. Strings are single quoted and escaped, NaN and infinite
+// floats are rejected.
+func QuoteLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case Ident:
+		return QuoteIdentifier(string(v))
+	case string:
+		return quoteString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return quoteFloat(float64(v), 32)
+	case float64:
+		return quoteFloat(v, 64)
+	case time.Time:
+		return quoteString(v.UTC().Format("2006-01-02 15:04:05")), nil
+	case fmt.Stringer:
+		return quoteString(v.String()), nil
+	default:
+		return "", fmt.Errorf("unsupported literal type %T", value)
+	}
+}
+
+// quoteFloat formats a float, NaN and infinity have no PPL literal
+func quoteFloat(f float64, bitSize int) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("unsupported literal %v", f)
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, bitSize), nil
+}
+
+func quoteString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+
+	return "'" + s + "'"
+}
+
+func quoteIdentifiers(names []string) ([]string, error) {
+	quoted := make([]string, len(names))
+
+	for i, name := range names {
+		q, err := QuoteIdentifier(name)
+		if err != nil {
+			return nil, err
+		}
+		quoted[i] = q
+	}
+
+	return quoted, nil
+}
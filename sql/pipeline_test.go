@@ -0,0 +1,192 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql_test
+
+import (
+	"github.com/WhizUs/go-opendistro/sql"
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"age", "age"},
+		{"address.city", "address.city"},
+		{"@timestamp", "@timestamp"},
+		{"_id", "_id"},
+		{"logs-2021.*", "`logs-2021.*`"},
+		{"first name", "`first name`"},
+		{"a' or '1'='1", "`a' or '1'='1`"},
+		{"x | delete", "`x | delete`"},
+	} {
+		got, err := sql.QuoteIdentifier(test.name)
+		if err != nil {
+			t.Errorf("QuoteIdentifier(%q): %s", test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("QuoteIdentifier(%q) = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestQuoteIdentifierErrors(t *testing.T) {
+	for _, name := range []string{"", "a`b", "a` | source=secret | fields `b", "line\nbreak", "cr\r"} {
+		if got, err := sql.QuoteIdentifier(name); err == nil {
+			t.Errorf("QuoteIdentifier(%q) = %s, want an error", name, got)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	for _, test := range []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "null"},
+		{"abc", "'abc'"},
+		{"", "''"},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{`trailing\`, `'trailing\\'`},
+		{`\' or 1=1 or '`, `'\\\' or 1=1 or \''`},
+		{"x' | source=secret | fields '", `'x\' | source=secret | fields \''`},
+		{"double \"quotes\"", `'double "quotes"'`},
+		{true, "true"},
+		{42, "42"},
+		{int8(-8), "-8"},
+		{int64(math.MaxInt64), "9223372036854775807"},
+		{uint64(math.MaxUint64), "18446744073709551615"},
+		{float32(1.5), "1.5"},
+		{0.000001, "0.000001"},
+		{1e21, "1000000000000000000000"},
+		{time.Date(2021, 3, 4, 5, 6, 7, 0, time.FixedZone("CET", 3600)), "'2021-03-04 04:06:07'"},
+		{net.ParseIP("10.0.0.1"), "'10.0.0.1'"},
+		{sql.Ident("age"), "age"},
+		{sql.Ident("first name"), "`first name`"},
+	} {
+		got, err := sql.QuoteLiteral(test.value)
+		if err != nil {
+			t.Errorf("QuoteLiteral(%#v): %s", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("QuoteLiteral(%#v) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestQuoteLiteralErrors(t *testing.T) {
+	for _, value := range []interface{}{
+		math.NaN(),
+		math.Inf(1),
+		math.Inf(-1),
+		float32(math.Inf(1)),
+		[]string{"a"},
+		struct{}{},
+		sql.Ident("a`b"),
+	} {
+		if got, err := sql.QuoteLiteral(value); err == nil {
+			t.Errorf("QuoteLiteral(%#v) = %s, want an error", value, got)
+		}
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	for _, test := range []struct {
+		pipeline *sql.Pipeline
+		want     string
+	}{
+		{
+			sql.Source("accounts").
+				Where("age > ? and state = ?", 30, "CA").
+				Stats([]string{"avg(balance)"}, "gender").
+				Sort("-gender"),
+			"source=accounts | where age > 30 and state = 'CA' | stats avg(balance) by gender | sort -gender",
+		},
+		{
+			sql.Source("logs-*", "metrics").Fields("@timestamp", "host name").Head(10),
+			"source=`logs-*`, metrics | fields @timestamp, `host name` | head 10",
+		},
+		{
+			sql.Source("accounts").ExcludeFields("ssn").Dedup(2, "state", "city").Rename("city", "town"),
+			"source=accounts | fields - ssn | dedup 2 state, city | rename city as town",
+		},
+		{
+			sql.Source("accounts").Eval("double age", "age * ?", 2).Top(3, []string{"state"}, "gender").Rare([]string{"city"}),
+			"source=accounts | eval `double age` = age * 2 | top 3 state by gender | rare city",
+		},
+		{
+			sql.Source("accounts").Where("? = ?", sql.Ident("first name"), "x"),
+			"source=accounts | where `first name` = 'x'",
+		},
+		{
+			// placeholders inside of quoted strings and identifiers are not replaced
+			sql.Source("accounts").Where("name = '?' and `?` = ? and note = 'it\\'s ?'", 1),
+			"source=accounts | where name = '?' and `?` = 1 and note = 'it\\'s ?'",
+		},
+		{
+			// a string argument can not terminate the literal and append commands
+			sql.Source("accounts").Where("name = ?", "x' | source=secret | where '1'='1"),
+			`source=accounts | where name = 'x\' | source=secret | where \'1\'=\'1'`,
+		},
+		{
+			sql.Source("accounts").Command("ad shingle_size=?", 8),
+			"source=accounts | ad shingle_size=8",
+		},
+	} {
+		got, err := test.pipeline.Build()
+		if err != nil {
+			t.Errorf("Build() of %q: %s", test.want, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Build() = %s\nwant %s", got, test.want)
+		}
+		if s := test.pipeline.String(); s != got {
+			t.Errorf("String() = %s, want %s", s, got)
+		}
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	for name, pipeline := range map[string]*sql.Pipeline{
+		"no source index":         sql.Source(),
+		"invalid source index":    sql.Source("a`b"),
+		"missing argument":        sql.Source("a").Where("x = ? and y = ?", 1),
+		"extra argument":          sql.Source("a").Where("x = ?", 1, 2),
+		"NaN argument":            sql.Source("a").Where("x = ?", math.NaN()),
+		"infinite argument":       sql.Source("a").Where("x > ?", math.Inf(-1)),
+		"unsupported argument":    sql.Source("a").Where("x = ?", []int{1}),
+		"no fields":               sql.Source("a").Fields(),
+		"no excluded fields":      sql.Source("a").ExcludeFields(),
+		"invalid eval field":      sql.Source("a").Eval("a`b", "1"),
+		"no aggregations":         sql.Source("a").Stats(nil),
+		"invalid group":           sql.Source("a").Stats([]string{"count()"}, "a`b"),
+		"no sort fields":          sql.Source("a").Sort(),
+		"invalid sort field":      sql.Source("a").Sort("-a`b"),
+		"dedup without fields":    sql.Source("a").Dedup(1),
+		"dedup with zero count":   sql.Source("a").Dedup(0, "x"),
+		"dedup with negative":     sql.Source("a").Dedup(-1, "x"),
+		"head with zero count":    sql.Source("a").Head(0),
+		"invalid rename":          sql.Source("a").Rename("x", "a`b"),
+		"invalid top field":       sql.Source("a").Top(1, []string{"a`b"}),
+		"rare without fields":     sql.Source("a").Rare(nil),
+		"first error is returned": sql.Source("a").Head(0).Fields("x"),
+	} {
+		if got, err := pipeline.Build(); err == nil {
+			t.Errorf("%s: Build() = %s, want an error", name, got)
+		}
+		if s := pipeline.String(); s != "" {
+			t.Errorf("%s: String() = %s, want an empty string", name, s)
+		}
+	}
+}
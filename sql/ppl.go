@@ -0,0 +1,57 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type PPLService common.Service
+
+type PPLServiceInterface interface {
+	Query(ctx context.Context, query string) (*Response, error)
+	Explain(ctx context.Context, query string) (json.RawMessage, error)
+}
+
+type pplRequest struct {
+	Query string `json:"query"`
+}
+
+// Query runs a PPL query. The result has the same schema and datarows structure as a SQL query.
+// Queries can be composed safely with Source.
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ppl/endpoint/
+func (s *PPLService) Query(ctx context.Context, query string) (*Response, error) {
+	ctx, done := s.Client.StartOperation(ctx, "ppl.query")
+	defer done()
+
+	body, err := s.Client.Do(ctx, &pplRequest{Query: query}, common.PPLEndpoint, http.MethodPost)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(body)
+}
+
+// Explain returns the execution plan of a PPL query
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/ppl/endpoint/
+func (s *PPLService) Explain(ctx context.Context, query string) (json.RawMessage, error) {
+	ctx, done := s.Client.StartOperation(ctx, "ppl.explain")
+	defer done()
+
+	endpoint := common.PPLEndpoint + "/_explain"
+
+	body, err := s.Client.Do(ctx, &pplRequest{Query: query}, endpoint, http.MethodPost)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
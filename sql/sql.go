@@ -0,0 +1,136 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+)
+
+type SQLService common.Service
+
+type SQLServiceInterface interface {
+	Query(ctx context.Context, request *Request) (*Response, error)
+	Next(ctx context.Context, cursor string) (*Response, error)
+	Close(ctx context.Context, cursor string) error
+	Explain(ctx context.Context, request *Request) (json.RawMessage, error)
+}
+
+type Request struct {
+	Query      string      `json:"query"`
+	FetchSize  int         `json:"fetch_size,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"`
+	Filter     interface{} `json:"filter,omitempty"`
+}
+
+type Parameter struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type cursorRequest struct {
+	Cursor string `json:"cursor"`
+}
+
+// Response is the JDBC formatted result of a query
+type Response struct {
+	Schema   []Column        `json:"schema"`
+	DataRows [][]interface{} `json:"datarows"`
+	Total    int64           `json:"total"`
+	Size     int64           `json:"size"`
+	Status   int             `json:"status"`
+	Cursor   string          `json:"cursor,omitempty"`
+}
+
+type Column struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	Type  string `json:"type"`
+}
+
+// Query runs a SQL query and returns the first page of the result. If a fetch size is given and the result
+// is larger than that, the response carries a cursor to retrieve the next page with Next.
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/sql/endpoints/
+func (s *SQLService) Query(ctx context.Context, request *Request) (*Response, error) {
+	ctx, done := s.Client.StartOperation(ctx, "sql.query")
+	defer done()
+
+	endpoint := common.SQLEndpoint + "?format=jdbc"
+
+	body, err := s.Client.Do(ctx, request, endpoint, http.MethodPost)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(body)
+}
+
+// Next fetches the page of a result referenced by the cursor of a previous response
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/sql/endpoints/#cursor
+func (s *SQLService) Next(ctx context.Context, cursor string) (*Response, error) {
+	ctx, done := s.Client.StartOperation(ctx, "sql.next")
+	defer done()
+
+	endpoint := common.SQLEndpoint + "?format=jdbc"
+
+	body, err := s.Client.Do(ctx, &cursorRequest{Cursor: cursor}, endpoint, http.MethodPost)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeResponse(body)
+}
+
+// Close releases the resources held by a cursor which has not been read until the end
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/sql/endpoints/#cursor
+func (s *SQLService) Close(ctx context.Context, cursor string) error {
+	ctx, done := s.Client.StartOperation(ctx, "sql.close")
+	defer done()
+
+	endpoint := common.SQLEndpoint + "/close"
+
+	_, err := s.Client.Do(ctx, &cursorRequest{Cursor: cursor}, endpoint, http.MethodPost)
+
+	return err
+}
+
+// Explain returns the Elasticsearch query DSL a SQL query is translated to
+//
+// see: https://opendistro.github.io/for-elasticsearch-docs/docs/sql/endpoints/#explain
+func (s *SQLService) Explain(ctx context.Context, request *Request) (json.RawMessage, error) {
+	ctx, done := s.Client.StartOperation(ctx, "sql.explain")
+	defer done()
+
+	endpoint := common.SQLEndpoint + "/_explain"
+
+	body, err := s.Client.Do(ctx, request, endpoint, http.MethodPost)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// decodeResponse keeps numbers as json.Number, so long values do not lose precision before they get
+// converted by the column type.
+func decodeResponse(body []byte) (*Response, error) {
+	var response *Response
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
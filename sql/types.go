@@ -0,0 +1,115 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+}
+
+var (
+	boolType      = reflect.TypeOf(false)
+	int64Type     = reflect.TypeOf(int64(0))
+	float64Type   = reflect.TypeOf(float64(0))
+	stringType    = reflect.TypeOf("")
+	timeType      = reflect.TypeOf(time.Time{})
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// GoType returns the Go type values of the given schema type are converted to by Convert
+func GoType(schemaType string) reflect.Type {
+	switch strings.ToLower(schemaType) {
+	case "boolean":
+		return boolType
+	case "byte", "short", "integer", "long":
+		return int64Type
+	case "half_float", "float", "scaled_float", "double":
+		return float64Type
+	case "keyword", "text", "string", "ip", "interval":
+		return stringType
+	case "date", "timestamp", "time", "date_nanos":
+		return timeType
+	default:
+		return interfaceType
+	}
+}
+
+// Convert converts a value of a datarow to the Go type of its schema type (see GoType). Objects, nested
+// fields, arrays and unknown types are returned as decoded by encoding/json.
+func Convert(schemaType string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch GoType(schemaType) {
+	case boolType:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	case int64Type:
+		switch v := value.(type) {
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return i, nil
+			}
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return int64(f), nil
+		case float64:
+			return int64(v), nil
+		}
+	case float64Type:
+		switch v := value.(type) {
+		case json.Number:
+			return v.Float64()
+		case float64:
+			return v, nil
+		}
+	case stringType:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return fmt.Sprint(value), nil
+	case timeType:
+		switch v := value.(type) {
+		case string:
+			return parseTimestamp(v)
+		case json.Number:
+			millis, err := v.Int64()
+			if err != nil {
+				return nil, err
+			}
+			return time.Unix(0, millis*int64(time.Millisecond)).UTC(), nil
+		}
+	default:
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("cannot convert %T to %s", value, schemaType)
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse timestamp %q", value)
+}
@@ -0,0 +1,86 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sql_test
+
+import (
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoType(t *testing.T) {
+	for schemaType, want := range map[string]reflect.Type{
+		"boolean":      reflect.TypeOf(false),
+		"BYTE":         reflect.TypeOf(int64(0)),
+		"integer":      reflect.TypeOf(int64(0)),
+		"long":         reflect.TypeOf(int64(0)),
+		"scaled_float": reflect.TypeOf(float64(0)),
+		"double":       reflect.TypeOf(float64(0)),
+		"keyword":      reflect.TypeOf(""),
+		"text":         reflect.TypeOf(""),
+		"ip":           reflect.TypeOf(""),
+		"timestamp":    reflect.TypeOf(time.Time{}),
+		"date":         reflect.TypeOf(time.Time{}),
+		"object":       reflect.TypeOf((*interface{})(nil)).Elem(),
+		"geo_point":    reflect.TypeOf((*interface{})(nil)).Elem(),
+	} {
+		if got := sql.GoType(schemaType); got != want {
+			t.Errorf("GoType(%q) = %v, want %v", schemaType, got, want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	for _, test := range []struct {
+		schemaType string
+		value      interface{}
+		want       interface{}
+	}{
+		{"long", nil, nil},
+		{"boolean", true, true},
+		{"long", json.Number("9007199254740993"), int64(9007199254740993)},
+		{"integer", json.Number("42.0"), int64(42)},
+		{"integer", float64(7), int64(7)},
+		{"double", json.Number("1.5"), 1.5},
+		{"float", float64(2.5), 2.5},
+		{"keyword", "abc", "abc"},
+		{"text", json.Number("12"), "12"},
+		{"timestamp", "2021-03-04 05:06:07.123", time.Date(2021, 3, 4, 5, 6, 7, 123000000, time.UTC)},
+		{"timestamp", "2021-03-04T05:06:07Z", time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"date", "2021-03-04", time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"date", json.Number("1614834367000"), time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"nested", []interface{}{"a"}, []interface{}{"a"}},
+	} {
+		got, err := sql.Convert(test.schemaType, test.value)
+		if err != nil {
+			t.Errorf("Convert(%q, %v): %s", test.schemaType, test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Convert(%q, %v) = %#v, want %#v", test.schemaType, test.value, got, test.want)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	for _, test := range []struct {
+		schemaType string
+		value      interface{}
+	}{
+		{"boolean", "true"},
+		{"long", "42"},
+		{"long", json.Number("x")},
+		{"double", true},
+		{"timestamp", "yesterday"},
+		{"timestamp", true},
+	} {
+		if got, err := sql.Convert(test.schemaType, test.value); err == nil {
+			t.Errorf("Convert(%q, %v) = %v, want an error", test.schemaType, test.value, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro"
+	odsql "github.com/WhizUs/go-opendistro/sql"
+	"time"
+)
+
+var (
+	ErrNotSupported = errors.New("not supported by the OpenDistro SQL endpoint")
+	ErrNamedArgs    = errors.New("named arguments are not supported, use ? placeholders")
+)
+
+type conn struct {
+	client    *opendistro.Client
+	fetchSize int
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, ErrNotSupported
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	parameters, err := toParameters(args)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.client.SQL.Query(ctx, &odsql.Request{
+		Query:      query,
+		FetchSize:  c.fetchSize,
+		Parameters: parameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newRows(ctx, c.client.SQL, response), nil
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1 as placeholders are resolved by the SQL plugin
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	namedArgs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		namedArgs[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+
+	return s.conn.QueryContext(context.Background(), s.query, namedArgs)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func toParameters(args []driver.NamedValue) ([]odsql.Parameter, error) {
+	var parameters []odsql.Parameter
+
+	for _, arg := range args {
+		if arg.Name != "" {
+			return nil, ErrNamedArgs
+		}
+
+		var parameter odsql.Parameter
+
+		switch v := arg.Value.(type) {
+		case nil:
+			parameter = odsql.Parameter{Type: "null"}
+		case int64:
+			parameter = odsql.Parameter{Type: "long", Value: v}
+		case float64:
+			parameter = odsql.Parameter{Type: "double", Value: v}
+		case bool:
+			parameter = odsql.Parameter{Type: "boolean", Value: v}
+		case string:
+			parameter = odsql.Parameter{Type: "string", Value: v}
+		case []byte:
+			parameter = odsql.Parameter{Type: "string", Value: string(v)}
+		case time.Time:
+			parameter = odsql.Parameter{Type: "timestamp", Value: v.UTC().Format("2006-01-02 15:04:05.000")}
+		default:
+			return nil, fmt.Errorf("unsupported argument type %T", arg.Value)
+		}
+
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters, nil
+}
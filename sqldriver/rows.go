@@ -0,0 +1,142 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	odsql "github.com/WhizUs/go-opendistro/sql"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// rows streams the result of a query. Further pages are fetched using the cursor of the previous
+// response as soon as the rows of the current page have been consumed.
+type rows struct {
+	ctx      context.Context
+	service  odsql.SQLServiceInterface
+	schema   []odsql.Column
+	dataRows [][]interface{}
+	index    int
+	cursor   string
+}
+
+func newRows(ctx context.Context, service odsql.SQLServiceInterface, response *odsql.Response) *rows {
+	return &rows{
+		ctx:      ctx,
+		service:  service,
+		schema:   response.Schema,
+		dataRows: response.DataRows,
+		cursor:   response.Cursor,
+	}
+}
+
+func (r *rows) Columns() []string {
+	columns := make([]string, len(r.schema))
+
+	for i, column := range r.schema {
+		if column.Alias != "" {
+			columns[i] = column.Alias
+		} else {
+			columns[i] = column.Name
+		}
+	}
+
+	return columns
+}
+
+func (r *rows) Close() error {
+	if r.cursor == "" {
+		return nil
+	}
+
+	cursor := r.cursor
+	r.cursor = ""
+	r.dataRows = nil
+
+	// the cursor has to be released even if the context of the query is already done
+	return r.service.Close(context.Background(), cursor)
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	for r.index >= len(r.dataRows) {
+		if r.cursor == "" {
+			return io.EOF
+		}
+
+		response, err := r.service.Next(r.ctx, r.cursor)
+		if err != nil {
+			return err
+		}
+
+		r.dataRows = response.DataRows
+		r.index = 0
+		r.cursor = response.Cursor
+
+		if len(r.dataRows) == 0 {
+			r.cursor = ""
+			return io.EOF
+		}
+	}
+
+	row := r.dataRows[r.index]
+	r.index++
+
+	for i := range dest {
+		if i >= len(row) || i >= len(r.schema) {
+			dest[i] = nil
+			continue
+		}
+
+		value, err := toValue(r.schema[i].Type, row[i])
+		if err != nil {
+			return err
+		}
+
+		dest[i] = value
+	}
+
+	return nil
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(r.schema[index].Type)
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	t := odsql.GoType(r.schema[index].Type)
+	if t.Kind() == reflect.Interface {
+		return bytesType
+	}
+
+	return t
+}
+
+// toValue converts a datarow value to a driver.Value. Values which are not representable as one of the
+// driver types (objects, nested fields and arrays) are passed as JSON.
+func toValue(schemaType string, value interface{}) (driver.Value, error) {
+	converted, err := odsql.Convert(schemaType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := converted.(type) {
+	case nil, int64, float64, bool, string, time.Time:
+		return v, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	default:
+		return json.Marshal(v)
+	}
+}
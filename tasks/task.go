@@ -0,0 +1,199 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/WhizUs/go-opendistro/common"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is the interval a task is polled with by WaitForTask if none is given
+const DefaultPollInterval = 5 * time.Second
+
+type TaskService common.Service
+
+type TaskServiceInterface interface {
+	List(ctx context.Context, options *ListOptions) ([]Task, error)
+	Get(ctx context.Context, id string) (*TaskResult, error)
+	Cancel(ctx context.Context, id string) error
+	WaitForTask(ctx context.Context, id string, pollInterval time.Duration) (*TaskResult, error)
+}
+
+type ListOptions struct {
+	// Actions to filter by, wildcards are supported (f.e. "*reindex")
+	Actions      []string
+	Nodes        []string
+	ParentTaskID string
+
+	// Detailed includes the description and the status of the tasks
+	Detailed bool
+}
+
+// Task is a running (or completed) task. Status depends on the action, f.e. the progress of a reindex.
+type Task struct {
+	Node               string            `json:"node"`
+	ID                 int64             `json:"id"`
+	Type               string            `json:"type"`
+	Action             string            `json:"action"`
+	Status             json.RawMessage   `json:"status,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	StartTimeInMillis  int64             `json:"start_time_in_millis"`
+	RunningTimeInNanos int64             `json:"running_time_in_nanos"`
+	Cancellable        bool              `json:"cancellable"`
+	ParentTaskID       string            `json:"parent_task_id,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+}
+
+// TaskResult of a task, Response is set when a successful task is completed and Error if it failed
+type TaskResult struct {
+	Completed bool            `json:"completed"`
+	Task      Task            `json:"task"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Error     *TaskError      `json:"error,omitempty"`
+}
+
+type TaskError struct {
+	Type     string     `json:"type"`
+	Reason   string     `json:"reason"`
+	CausedBy *TaskError `json:"caused_by,omitempty"`
+}
+
+type listResponse struct {
+	Tasks []Task `json:"tasks"`
+}
+
+func (e *TaskError) Error() string {
+	msg := e.Type + ": " + e.Reason
+
+	if e.CausedBy != nil {
+		msg = msg + " (caused by " + e.CausedBy.Error() + ")"
+	}
+
+	return msg
+}
+
+// Decode unmarshals the response of a completed task, f.e. into a document.ByQueryResponse
+func (r *TaskResult) Decode(v interface{}) error {
+	return json.Unmarshal(r.Response, v)
+}
+
+// List the tasks running on the cluster
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/tasks.html
+func (s *TaskService) List(ctx context.Context, options *ListOptions) ([]Task, error) {
+	ctx, done := s.Client.StartOperation(ctx, "tasks.list")
+	defer done()
+
+	params := url.Values{}
+	params.Set("group_by", "none")
+
+	if options != nil {
+		if len(options.Actions) > 0 {
+			params.Set("actions", strings.Join(options.Actions, ","))
+		}
+		if len(options.Nodes) > 0 {
+			params.Set("nodes", strings.Join(options.Nodes, ","))
+		}
+		if options.ParentTaskID != "" {
+			params.Set("parent_task_id", options.ParentTaskID)
+		}
+		if options.Detailed {
+			params.Set("detailed", "true")
+		}
+	}
+
+	endpoint := common.TasksEndpoint + "?" + params.Encode()
+
+	var response *listResponse
+
+	err := s.Client.Get(ctx, endpoint, &response)
+	if err != nil || response == nil {
+		return nil, err
+	}
+
+	return response.Tasks, nil
+}
+
+// Get a task by id (f.e. "oTUltX4IQMOUUVeiohTt8A:12345"), an empty response is returned as error
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/tasks.html
+func (s *TaskService) Get(ctx context.Context, id string) (*TaskResult, error) {
+	ctx, done := s.Client.StartOperation(ctx, "tasks.get")
+	defer done()
+
+	// without an id the tasks would be listed
+	if id == "" {
+		return nil, errors.New("task id is required")
+	}
+
+	endpoint := common.TasksEndpoint + "/" + id
+
+	var result *TaskResult
+
+	err := s.Client.Get(ctx, endpoint, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if result == nil {
+		return nil, fmt.Errorf("empty response for task %s", id)
+	}
+
+	return result, nil
+}
+
+// Cancel a task, only cancellable tasks can be canceled
+//
+// see: https://www.elastic.co/guide/en/elasticsearch/reference/7.10/tasks.html#task-cancellation
+func (s *TaskService) Cancel(ctx context.Context, id string) error {
+	ctx, done := s.Client.StartOperation(ctx, "tasks.cancel")
+	defer done()
+
+	endpoint := common.TasksEndpoint + "/" + id + "/_cancel"
+
+	_, err := s.Client.Do(ctx, nil, endpoint, http.MethodPost)
+
+	return err
+}
+
+// WaitForTask polls a task until it is completed. If the task failed, the result is returned with its
+// TaskError as error. A poll interval <= 0 defaults to DefaultPollInterval.
+func (s *TaskService) WaitForTask(ctx context.Context, id string, pollInterval time.Duration) (*TaskResult, error) {
+	ctx, done := s.Client.StartOperation(ctx, "tasks.wait_for_task")
+	defer done()
+
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	for {
+		result, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Completed {
+			if result.Error != nil {
+				return result, result.Error
+			}
+
+			return result, nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
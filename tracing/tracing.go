@@ -0,0 +1,167 @@
+// Copyright 2019 WhizUs GmbH. All rights reserved.
+//
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/WhizUs/go-opendistro/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+const (
+	InstrumentationName = "github.com/WhizUs/go-opendistro"
+
+	OperationKey   = attribute.Key("opendistro.operation")
+	AttemptKey     = attribute.Key("opendistro.attempt")
+	ErrorReasonKey = attribute.Key("opendistro.error.reason")
+)
+
+type Options struct {
+	// TracerProvider creates the tracer, defaults to the global provider of otel
+	TracerProvider trace.TracerProvider
+
+	// Propagator injects the trace context into the requests, defaults to the W3C trace context
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracer traces the requests of a client, it is passed to the client in ClientConfig.Instrumentations.
+// Every service method is traced as span named after it (f.e. "RoleService.Create") with a child span
+// for every HTTP attempt of its requests including the retries. Requests which were not sent by a service
+// are traced as span of their own.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+type errorResponse struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// NewTracer creates a tracer with the provider of the options
+func NewTracer(options *Options) *Tracer {
+	opts := Options{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.Propagator == nil {
+		opts.Propagator = propagation.TraceContext{}
+	}
+
+	return &Tracer{
+		tracer:     opts.TracerProvider.Tracer(InstrumentationName),
+		propagator: opts.Propagator,
+	}
+}
+
+// StartOperation implements common.Instrumentation
+func (t *Tracer) StartOperation(ctx context.Context, operation *common.OperationInfo) (context.Context, func()) {
+	name := operation.Func
+	if name == "" {
+		name = operation.Operation
+	}
+
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(OperationKey.String(operation.Operation)),
+	)
+
+	return ctx, func() {
+		span.End()
+	}
+}
+
+// StartRequest implements common.Instrumentation, the result of a request is recorded on the span of the
+// service method which sent it
+func (t *Tracer) StartRequest(ctx context.Context, request *common.RequestInfo) (context.Context, func(response *common.ResponseInfo)) {
+	var span trace.Span
+
+	if request.Operation == "" {
+		ctx, span = t.tracer.Start(ctx, "HTTP "+request.Method, trace.WithSpanKind(trace.SpanKindInternal))
+	} else {
+		span = trace.SpanFromContext(ctx)
+	}
+
+	return ctx, func(response *common.ResponseInfo) {
+		if request.Operation == "" {
+			defer span.End()
+		}
+
+		if response.Err != nil {
+			span.RecordError(response.Err)
+			span.SetStatus(codes.Error, response.Err.Error())
+			return
+		}
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(response.StatusCode))
+
+		if response.StatusCode >= http.StatusBadRequest {
+			reason := errorReason(response.StatusCode, response.Body)
+			if reason != "" {
+				span.SetAttributes(ErrorReasonKey.String(reason))
+			}
+
+			span.SetStatus(codes.Error, reason)
+		}
+	}
+}
+
+// StartAttempt implements common.Instrumentation, it injects the trace context into the request
+func (t *Tracer) StartAttempt(req *http.Request, attempt int) (*http.Request, func(resp *http.Response, err error)) {
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.HTTPClientAttributesFromHTTPRequest(req)...),
+		trace.WithAttributes(AttemptKey.Int(attempt)),
+	)
+
+	// the request may not be modified by a transport, its headers are shared with the caller
+	req = req.Clone(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	return req, func(resp *http.Response, err error) {
+		defer span.End()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return
+		}
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+	}
+}
+
+// errorReason returns the reason of an error response, either of an Elasticsearch style error object or
+// of the status response of the security plugin
+func errorReason(statusCode int, body []byte) string {
+	if re := common.NewResponseError(statusCode, body); re != nil {
+		return re.Reason
+	}
+
+	var er errorResponse
+
+	if err := json.Unmarshal(body, &er); err == nil {
+		if er.Message != "" {
+			return er.Message
+		}
+		if er.Reason != "" {
+			return er.Reason
+		}
+	}
+
+	return http.StatusText(statusCode)
+}